@@ -9,53 +9,28 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/jondmarien/eco-market/services/order-service/internal/app"
 	"github.com/jondmarien/eco-market/services/order-service/internal/config"
-	"github.com/jondmarien/eco-market/services/order-service/internal/database"
-	"github.com/jondmarien/eco-market/services/order-service/internal/handlers"
-	"github.com/gorilla/mux"
 )
 
 func main() {
-	// Load configuration
 	cfg := config.Load()
 
-	// Initialize database connections
-	db, err := database.NewPostgreSQL(cfg.PostgreSQLURL)
+	application, err := app.New(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+		log.Fatalf("Failed to initialize application: %v", err)
 	}
-	defer db.Close()
+	defer application.Close()
 
-	redisClient, err := database.NewRedis(cfg.RedisURL)
-	if err != nil {
-		log.Fatalf("Failed to connect to Redis: %v", err)
-	}
-	defer redisClient.Close()
-
-	// Initialize handlers
-	orderHandler := handlers.NewOrderHandler(db, redisClient)
+	logger := application.Logger
 
-	// Setup routes
-	router := mux.NewRouter()
-	api := router.PathPrefix("/api/v1").Subrouter()
-	
-	// Order routes
-	api.HandleFunc("/orders", orderHandler.CreateOrder).Methods("POST")
-	api.HandleFunc("/orders", orderHandler.GetOrders).Methods("GET")
-	api.HandleFunc("/orders/{id}", orderHandler.GetOrder).Methods("GET")
-	api.HandleFunc("/orders/{id}", orderHandler.UpdateOrder).Methods("PUT")
-	api.HandleFunc("/orders/{id}/status", orderHandler.UpdateOrderStatus).Methods("PATCH")
-	
-	// Health check
-	api.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"healthy"}`))
-	}).Methods("GET")
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go application.Run(workerCtx)
 
-	// Start server
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
-		Handler:      router,
+		Handler:      application.Router,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -63,9 +38,9 @@ func main() {
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Order Service starting on port %s", cfg.Port)
+		logger.Infof("Order Service starting on port %s", cfg.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
+			logger.Fatalf("Server failed to start: %v", err)
 		}
 	}()
 
@@ -73,15 +48,16 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	logger.Info("Shutting down server...")
+	stopWorkers()
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		logger.Fatalf("Server forced to shutdown: %v", err)
 	}
 
-	log.Println("Server exited")
+	logger.Info("Server exited")
 }
@@ -0,0 +1,196 @@
+// Package app assembles the order service's layers — config, logger, storage,
+// repository, services, and HTTP handlers — into a single App, so main.go is a thin
+// "load config, build App, run it" shell instead of hand-wiring every dependency
+// itself.
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/mux"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sirupsen/logrus"
+
+	"github.com/jondmarien/eco-market/services/order-service/internal/config"
+	"github.com/jondmarien/eco-market/services/order-service/internal/database"
+	"github.com/jondmarien/eco-market/services/order-service/internal/events"
+	"github.com/jondmarien/eco-market/services/order-service/internal/handlers"
+	"github.com/jondmarien/eco-market/services/order-service/internal/middleware"
+	"github.com/jondmarien/eco-market/services/order-service/internal/repository"
+	"github.com/jondmarien/eco-market/services/order-service/internal/service"
+	"github.com/jondmarien/eco-market/services/order-service/internal/webhooks"
+	"github.com/jondmarien/eco-market/services/order-service/internal/websocket"
+)
+
+// reservationReapInterval is how often the stock-reservation reaper sweeps for
+// expired holds.
+const reservationReapInterval = time.Minute
+
+// App holds every assembled dependency plus the background workers and router main()
+// needs to run the service.
+type App struct {
+	Config *config.Config
+	Logger *logrus.Entry
+	Router *mux.Router
+
+	orderService *service.OrderService
+	relay        *events.Relay
+	webhookPool  *webhooks.Pool
+
+	db    *sql.DB
+	redis *redis.Client
+	nats  *nats.Conn
+}
+
+// New connects to Postgres and Redis per cfg, builds the repository → service →
+// handler chain, and assembles the HTTP router. The caller owns the returned App's
+// lifecycle: call Run to start background workers and Close to release connections.
+func New(cfg *config.Config) (*App, error) {
+	logger := newLogger(cfg)
+
+	db, err := database.NewPostgreSQL(cfg.PostgreSQLURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	redisClient, err := database.NewRedis(cfg.RedisURL)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	webhookStore := webhooks.NewStore(db)
+	if err := webhookStore.InitializeSchema(context.Background()); err != nil {
+		db.Close()
+		redisClient.Close()
+		return nil, fmt.Errorf("failed to initialize webhook schema: %w", err)
+	}
+
+	natsConn, err := nats.Connect(cfg.NATSURL)
+	if err != nil {
+		db.Close()
+		redisClient.Close()
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(natsConn)
+	if err != nil {
+		db.Close()
+		redisClient.Close()
+		natsConn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	if _, err := events.EnsureOrdersStream(context.Background(), js, cfg.NATSStreamMaxAge); err != nil {
+		db.Close()
+		redisClient.Close()
+		natsConn.Close()
+		return nil, fmt.Errorf("failed to ensure orders stream: %w", err)
+	}
+
+	orderRepo := repository.NewOrderRepository(db, redisClient, webhookStore)
+	eventPublisher := websocket.NewRedisEventPublisher(redisClient)
+	// No StockReserver implementation lives in this repo yet: the inventory service
+	// it would call out to is a separate deployment. OrderService treats a nil
+	// reserver as "stock holds disabled" rather than failing every order.
+	orderService := service.NewOrderService(orderRepo, nil, eventPublisher)
+
+	orderHandler := handlers.NewOrderHandler(orderService)
+	webhookHandler := webhooks.NewHandler(webhookStore)
+	streamHandler := websocket.NewStreamHandler(redisClient)
+
+	relay := events.NewRelay(orderRepo, events.NewNATSPublisher(js), 2*time.Second)
+	webhookPool := webhooks.NewPool(webhookStore, cfg.WebhookWorkers, cfg.WebhookMaxAttempts)
+
+	return &App{
+		Config:       cfg,
+		Logger:       logger,
+		Router:       buildRouter(logger.Logger, orderHandler, webhookHandler, streamHandler),
+		orderService: orderService,
+		relay:        relay,
+		webhookPool:  webhookPool,
+		db:           db,
+		redis:        redisClient,
+		nats:         natsConn,
+	}, nil
+}
+
+// buildRouter wires every handler into the route table the service exposes.
+func buildRouter(logger *logrus.Logger, orderHandler *handlers.OrderHandler, webhookHandler *webhooks.Handler, streamHandler *websocket.StreamHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(middleware.Logging(logger), middleware.Recovery(logger))
+	api := router.PathPrefix("/api/v1").Subrouter()
+
+	// Order routes
+	api.HandleFunc("/orders", orderHandler.CreateOrder).Methods("POST")
+	api.HandleFunc("/orders", orderHandler.GetOrders).Methods("GET")
+	api.HandleFunc("/orders/stream", streamHandler.Stream).Methods("GET")
+	api.HandleFunc("/orders/{id}", orderHandler.GetOrder).Methods("GET")
+	api.HandleFunc("/orders/{id}", orderHandler.UpdateOrder).Methods("PUT")
+	api.HandleFunc("/orders/{id}/status", orderHandler.UpdateOrderStatus).Methods("PATCH")
+	api.HandleFunc("/orders/{id}/fulfillment", orderHandler.FulfillOrderItems).Methods("PATCH")
+	api.HandleFunc("/orders/{id}/items/{itemId}/refund", orderHandler.RefundOrderItem).Methods("POST")
+
+	// Health check
+	api.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"healthy"}`))
+	}).Methods("GET")
+
+	// Admin routes
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.HandleFunc("/orders", orderHandler.SearchOrders).Methods("GET")
+	admin.HandleFunc("/orders/stats", orderHandler.GetOrderStats).Methods("GET")
+
+	// Webhook subscription routes
+	api.HandleFunc("/webhooks", webhookHandler.CreateSubscription).Methods("POST")
+	api.HandleFunc("/webhooks", webhookHandler.ListSubscriptions).Methods("GET")
+	api.HandleFunc("/webhooks/{id}", webhookHandler.UpdateSubscription).Methods("PUT")
+	api.HandleFunc("/webhooks/{id}", webhookHandler.DeleteSubscription).Methods("DELETE")
+
+	return router
+}
+
+// Run starts every background worker (outbox relay, webhook delivery pool,
+// reservation reaper) and blocks until ctx is cancelled.
+func (a *App) Run(ctx context.Context) {
+	go a.relay.Run(ctx)
+	go a.webhookPool.Run(ctx)
+	go a.orderService.RunReservationReaper(ctx, reservationReapInterval)
+
+	<-ctx.Done()
+}
+
+// Close releases the database, Redis, and NATS connections.
+func (a *App) Close() {
+	a.db.Close()
+	a.redis.Close()
+	a.nats.Close()
+}
+
+// newLogger configures a logrus.Logger per cfg.LogLevel/LogFormat, defaulting to
+// info/JSON (suitable for Stackdriver/ELK-style ingestion) on invalid values, and
+// returns it as an Entry carrying the service/env fields every log line should have.
+func newLogger(cfg *config.Config) *logrus.Entry {
+	logger := logrus.New()
+	logger.SetOutput(os.Stdout)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	if cfg.LogFormat == "text" {
+		logger.SetFormatter(&logrus.TextFormatter{})
+	}
+
+	level, err := logrus.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	logger.SetLevel(level)
+
+	return logger.WithField("service", "order-service").WithField("env", cfg.Environment)
+}
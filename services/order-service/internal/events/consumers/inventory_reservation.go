@@ -0,0 +1,83 @@
+// Package consumers holds reference JetStream subscribers for the ORDERS stream, for
+// other services to model their own subscriptions on: a durable pull consumer with
+// explicit ack/nak and bounded redelivery.
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// inventoryReservationDurableName is the consumer's durable name: reusing it across
+// process restarts resumes the same consumer instead of creating a new one starting
+// from the stream head.
+const inventoryReservationDurableName = "inventory-reservation"
+
+// InventoryReservationConsumer durably consumes every orders.status.confirmed event
+// so a downstream inventory service can turn the stock holds placed at order creation
+// into permanent decrements. It's a reference implementation: other subscribers to
+// the ORDERS stream should follow the same shape (durable name, explicit ack, bounded
+// redelivery) rather than auto-ack.
+type InventoryReservationConsumer struct {
+	consumer jetstream.Consumer
+}
+
+// NewInventoryReservationConsumer creates (or resumes) a durable pull consumer on
+// stream, filtered to order confirmations. An unacked message is redelivered after
+// ackWait, up to maxDeliver times, before JetStream gives up on it.
+func NewInventoryReservationConsumer(ctx context.Context, stream jetstream.Stream, ackWait time.Duration, maxDeliver int) (*InventoryReservationConsumer, error) {
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       inventoryReservationDurableName,
+		FilterSubject: "orders.status.confirmed",
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       ackWait,
+		MaxDeliver:    maxDeliver,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s consumer: %w", inventoryReservationDurableName, err)
+	}
+
+	return &InventoryReservationConsumer{consumer: consumer}, nil
+}
+
+// Run processes messages until ctx is cancelled. Each event is only acked once
+// commitReservation succeeds; a failed commit is Nak'd so JetStream redelivers it
+// (up to MaxDeliver) instead of the event being silently lost.
+func (c *InventoryReservationConsumer) Run(ctx context.Context) error {
+	consumeCtx, err := c.consumer.Consume(func(msg jetstream.Msg) {
+		var event models.OrderEvent
+		if err := json.Unmarshal(msg.Data(), &event); err != nil {
+			log.Printf("inventory-reservation: failed to decode order event: %v", err)
+			_ = msg.Nak()
+			return
+		}
+
+		if err := commitReservation(event); err != nil {
+			log.Printf("inventory-reservation: failed to commit reservation for order %s: %v", event.OrderID, err)
+			_ = msg.Nak()
+			return
+		}
+
+		_ = msg.Ack()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start consuming %s: %w", inventoryReservationDurableName, err)
+	}
+	defer consumeCtx.Stop()
+
+	<-ctx.Done()
+	return nil
+}
+
+// commitReservation stands in for the real call into the inventory service; this
+// package only demonstrates the consumer shape other services should copy.
+func commitReservation(event models.OrderEvent) error {
+	log.Printf("inventory-reservation: committing reservation for order %s", event.OrderID)
+	return nil
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher publishes order events to a Kafka topic, keyed by order ID so
+// consumers see a per-order partition ordering guarantee.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher creates a Publisher backed by a Kafka writer for the given brokers/topic.
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.Hash{},
+		},
+	}
+}
+
+// Publish writes the event to Kafka, keyed by order ID.
+func (p *KafkaPublisher) Publish(ctx context.Context, event models.OrderEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order event: %w", err)
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(event.OrderID.String()),
+		Value: payload,
+	}
+
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish order event to kafka: %w", err)
+	}
+
+	return nil
+}
+
+// Close flushes and closes the underlying Kafka writer.
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// OrdersStreamName is the JetStream stream every order lifecycle event is published
+// to, with one subject per transition (orders.created, orders.status.<new_status>).
+const OrdersStreamName = "ORDERS"
+
+// EnsureOrdersStream declares (or updates) the ORDERS stream, retaining events for up
+// to maxAge regardless of whether downstream consumers have acked them yet.
+func EnsureOrdersStream(ctx context.Context, js jetstream.JetStream, maxAge time.Duration) (jetstream.Stream, error) {
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      OrdersStreamName,
+		Subjects:  []string{"orders.>"},
+		Retention: jetstream.LimitsPolicy,
+		MaxAge:    maxAge,
+		Storage:   jetstream.FileStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare %s stream: %w", OrdersStreamName, err)
+	}
+	return stream, nil
+}
+
+// NATSPublisher implements Publisher by publishing order events onto the ORDERS
+// JetStream stream, so downstream services (inventory, shipping, email) can subscribe
+// with at-least-once delivery instead of polling this service.
+type NATSPublisher struct {
+	js jetstream.JetStream
+}
+
+// NewNATSPublisher creates a Publisher backed by js.
+func NewNATSPublisher(js jetstream.JetStream) *NATSPublisher {
+	return &NATSPublisher{js: js}
+}
+
+// Publish publishes event to its lifecycle subject, deduplicated by JetStream over
+// its dedup window using a message ID derived from the order ID and the event's
+// per-order sequence number (the order ID alone would collide across that order's own
+// later events).
+func (p *NATSPublisher) Publish(ctx context.Context, event models.OrderEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order event: %w", err)
+	}
+
+	msg := &nats.Msg{
+		Subject: subjectForEvent(event),
+		Data:    payload,
+		Header:  nats.Header{},
+	}
+	msg.Header.Set("Nats-Msg-Id", fmt.Sprintf("%s:%d", event.OrderID, event.Sequence))
+
+	if _, err := p.js.PublishMsg(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish order event to NATS JetStream: %w", err)
+	}
+
+	return nil
+}
+
+// subjectForEvent maps an order event onto its ORDERS stream subject.
+func subjectForEvent(event models.OrderEvent) string {
+	if event.Type == models.OrderEventCreated {
+		return "orders.created"
+	}
+	return fmt.Sprintf("orders.status.%s", event.NewStatus)
+}
@@ -0,0 +1,14 @@
+// Package events publishes order lifecycle transitions to downstream consumers
+// (inventory, shipping, email) so they no longer need to poll the order service.
+package events
+
+import (
+	"context"
+
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+)
+
+// Publisher delivers an order lifecycle event to a message broker.
+type Publisher interface {
+	Publish(ctx context.Context, event models.OrderEvent) error
+}
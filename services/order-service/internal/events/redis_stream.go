@@ -0,0 +1,44 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+)
+
+// RedisStreamPublisher publishes order events onto a Redis Stream.
+type RedisStreamPublisher struct {
+	client *redis.Client
+	stream string
+}
+
+// NewRedisStreamPublisher creates a Publisher backed by the given Redis Stream key.
+func NewRedisStreamPublisher(client *redis.Client, stream string) *RedisStreamPublisher {
+	return &RedisStreamPublisher{client: client, stream: stream}
+}
+
+// Publish adds the event as an entry on the stream with two fields: the event type
+// and the JSON-encoded event payload.
+func (p *RedisStreamPublisher) Publish(ctx context.Context, event models.OrderEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order event: %w", err)
+	}
+
+	args := &redis.XAddArgs{
+		Stream: p.stream,
+		Values: map[string]interface{}{
+			"type":    string(event.Type),
+			"payload": payload,
+		},
+	}
+
+	if err := p.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("failed to publish order event to redis stream: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+)
+
+// OutboxStore is the persistence side of the transactional outbox pattern: rows
+// are written alongside the order mutation they describe, and the relay drains
+// them independently of whether the broker is reachable.
+type OutboxStore interface {
+	FetchUnpublishedOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	MarkOutboxEventDelivered(ctx context.Context, id uuid.UUID) error
+}
+
+// Relay polls an OutboxStore and publishes each unpublished event at-least-once,
+// so a broker outage never loses an order event even though the DB write already
+// committed.
+type Relay struct {
+	store     OutboxStore
+	publisher Publisher
+	interval  time.Duration
+	batchSize int
+}
+
+// NewRelay creates a Relay that polls the store every interval.
+func NewRelay(store OutboxStore, publisher Publisher, interval time.Duration) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		interval:  interval,
+		batchSize: 100,
+	}
+}
+
+// Run polls until ctx is cancelled. It's meant to be started as a background goroutine.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.relayOnce(ctx)
+		}
+	}
+}
+
+func (r *Relay) relayOnce(ctx context.Context) {
+	pending, err := r.store.FetchUnpublishedOutboxEvents(ctx, r.batchSize)
+	if err != nil {
+		log.Printf("events: failed to fetch outbox events: %v", err)
+		return
+	}
+
+	for _, outboxEvent := range pending {
+		if err := r.publisher.Publish(ctx, outboxEvent.Event); err != nil {
+			log.Printf("events: failed to publish outbox event %s: %v", outboxEvent.ID, err)
+			continue
+		}
+
+		if err := r.store.MarkOutboxEventDelivered(ctx, outboxEvent.ID); err != nil {
+			log.Printf("events: failed to mark outbox event %s delivered: %v", outboxEvent.ID, err)
+		}
+	}
+}
@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/google/uuid"
@@ -21,27 +27,72 @@ func NewOrderHandler(service *service.OrderService) *OrderHandler {
 	return &OrderHandler{service: service}
 }
 
-// CreateOrder handles order creation
+// CreateOrder handles order creation. An Idempotency-Key header lets a client safely
+// retry a POST (e.g. after a network timeout) without creating a duplicate order: a
+// retry with the same key and body replays the original order with an
+// Idempotent-Replay response header, while reusing the key with a different body
+// fails with 409 rather than silently returning the wrong order.
 func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.writeError(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
 	var req models.CreateOrderRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.Unmarshal(body, &req); err != nil {
 		h.writeError(w, "Invalid JSON payload", http.StatusBadRequest)
 		return
 	}
 
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	var requestHash string
+	if idempotencyKey != "" {
+		requestHash = hashIdempotentRequest(req.UserID, idempotencyKey, body)
+	}
+
 	// Create order using service
-	order, err := h.service.CreateOrder(r.Context(), req)
+	order, replayed, err := h.service.CreateOrder(r.Context(), req, idempotencyKey, requestHash)
 	if err != nil {
+		if errors.Is(err, service.ErrIdempotencyInFlight) {
+			w.Header().Set("Retry-After", "2")
+			h.writeError(w, "A request with this idempotency key is still being processed", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, service.ErrIdempotencyKeyConflict) {
+			h.writeError(w, "IdempotencyKeyConflict: this Idempotency-Key was already used with a different request body", http.StatusConflict)
+			return
+		}
 		h.writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Respond with created order
+	status := http.StatusCreated
+	message := "Order created successfully"
+	if replayed {
+		status = http.StatusOK
+		message = "Order already created for this idempotency key"
+		w.Header().Set("Idempotent-Replay", "true")
+	}
+
 	res := models.OrderResponse{
 		Order:   order,
-		Message: "Order created successfully",
+		Message: message,
 	}
-	h.writeJSON(w, res, http.StatusCreated)
+	h.writeJSON(w, res, status)
+}
+
+// hashIdempotentRequest computes the hex-encoded sha256 of userID, key, and the raw
+// request body, so CreateOrder can tell a safe-to-replay retry (identical inputs)
+// apart from a client reusing an Idempotency-Key across distinct requests.
+func hashIdempotentRequest(userID uuid.UUID, key string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(userID.String()))
+	h.Write([]byte("|"))
+	h.Write([]byte(key))
+	h.Write([]byte("|"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 // GetOrders retrieves orders for a user
@@ -172,6 +223,151 @@ func (h *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, res, http.StatusOK)
 }
 
+// FulfillOrderItems applies a batch of per-item fulfillment updates to an order.
+func (h *OrderHandler) FulfillOrderItems(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	orderID, err := uuid.Parse(params["id"])
+	if err != nil {
+		h.writeError(w, "Invalid order ID format", http.StatusBadRequest)
+		return
+	}
+
+	var req models.FulfillItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid JSON payload", http.StatusBadRequest)
+		return
+	}
+
+	newStatus, err := h.service.FulfillItems(r.Context(), orderID, req.Items)
+	if err != nil {
+		h.writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := map[string]string{"status": string(newStatus)}
+	h.writeJSON(w, res, http.StatusOK)
+}
+
+// RefundOrderItem refunds (fully or partially) a single order item.
+func (h *OrderHandler) RefundOrderItem(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	orderID, err := uuid.Parse(params["id"])
+	if err != nil {
+		h.writeError(w, "Invalid order ID format", http.StatusBadRequest)
+		return
+	}
+	itemID, err := uuid.Parse(params["itemId"])
+	if err != nil {
+		h.writeError(w, "Invalid item ID format", http.StatusBadRequest)
+		return
+	}
+
+	var req models.RefundItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid JSON payload", http.StatusBadRequest)
+		return
+	}
+
+	remaining, err := h.service.RefundItem(r.Context(), orderID, itemID, req.Quantity)
+	if err != nil {
+		h.writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := map[string]float64{"remaining_refundable_amount": remaining}
+	h.writeJSON(w, res, http.StatusOK)
+}
+
+// SearchOrders handles the admin order search endpoint, filtering by status, user,
+// created-at range, and total amount, with cursor-based pagination.
+func (h *OrderHandler) SearchOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := models.SearchFilter{
+		Currency: query.Get("currency"),
+		Cursor:   query.Get("cursor"),
+	}
+
+	if statusParam := query.Get("status"); statusParam != "" {
+		for _, s := range strings.Split(statusParam, ",") {
+			filter.Statuses = append(filter.Statuses, models.OrderStatus(s))
+		}
+	}
+
+	if userIDStr := query.Get("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			h.writeError(w, "Invalid user_id format", http.StatusBadRequest)
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	if from, err := parseQueryTime(query.Get("from")); err != nil {
+		h.writeError(w, "Invalid from date, expected RFC3339", http.StatusBadRequest)
+		return
+	} else if from != nil {
+		filter.CreatedFrom = from
+	}
+
+	if to, err := parseQueryTime(query.Get("to")); err != nil {
+		h.writeError(w, "Invalid to date, expected RFC3339", http.StatusBadRequest)
+		return
+	} else if to != nil {
+		filter.CreatedTo = to
+	}
+
+	if min, err := parseQueryFloat(query.Get("min_total")); err != nil {
+		h.writeError(w, "Invalid min_total", http.StatusBadRequest)
+		return
+	} else if min != nil {
+		filter.MinTotal = min
+	}
+
+	if max, err := parseQueryFloat(query.Get("max_total")); err != nil {
+		h.writeError(w, "Invalid max_total", http.StatusBadRequest)
+		return
+	} else if max != nil {
+		filter.MaxTotal = max
+	}
+
+	filter.Limit, _ = strconv.Atoi(query.Get("limit"))
+
+	orders, nextCursor, err := h.service.SearchOrders(r.Context(), filter)
+	if err != nil {
+		h.writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := map[string]interface{}{
+		"orders":      orders,
+		"next_cursor": nextCursor,
+	}
+	h.writeJSON(w, res, http.StatusOK)
+}
+
+func parseQueryTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func parseQueryFloat(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
 // GetOrderStats returns order statistics (admin endpoint)
 func (h *OrderHandler) GetOrderStats(w http.ResponseWriter, r *http.Request) {
 	stats, err := h.service.GetOrderStats(r.Context())
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey string
+
+const loggerContextKey contextKey = "logger"
+
+// LoggerFromContext returns the request-scoped logger injected by Logging, or a
+// standalone standard logger if none was injected (e.g. a handler called directly
+// outside the middleware chain).
+func LoggerFromContext(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(loggerContextKey).(*logrus.Entry); ok {
+		return entry
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+func withLogger(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerContextKey, entry)
+}
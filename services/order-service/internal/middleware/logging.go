@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// statusRecorder wraps http.ResponseWriter to capture the status code written, since
+// net/http gives handlers no way to read it back afterwards.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack delegates to the embedded ResponseWriter's http.Hijacker so wrapping with
+// statusRecorder stays transparent to connection upgrades (e.g. WebSocket), which
+// type-assert the ResponseWriter they're given down to http.Hijacker.
+func (w *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("underlying ResponseWriter does not implement http.Hijacker")
+	}
+	return hijacker.Hijack()
+}
+
+// Logging returns mux middleware that generates or propagates an X-Request-ID
+// header, injects a request-scoped logger into the request context, and emits one
+// structured line per request with method/path/status/latency fields.
+func Logging(logger *logrus.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(requestIDHeader)
+			if requestID == "" {
+				requestID = uuid.New().String()
+			}
+			w.Header().Set(requestIDHeader, requestID)
+
+			entry := logger.WithFields(logrus.Fields{
+				"request_id":  requestID,
+				"http.method": r.Method,
+				"http.path":   r.URL.Path,
+			})
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+
+			next.ServeHTTP(rec, r.WithContext(withLogger(r.Context(), entry)))
+
+			entry.WithFields(logrus.Fields{
+				"http.status": rec.status,
+				"latency_ms":  time.Since(start).Milliseconds(),
+			}).Info("request completed")
+		})
+	}
+}
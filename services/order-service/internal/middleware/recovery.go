@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Recovery returns mux middleware that recovers panics anywhere in the handler
+// chain, logs them as a structured error with a stack trace, and responds with 500
+// instead of taking down the whole process.
+func Recovery(logger *logrus.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					LoggerFromContext(r.Context()).WithFields(logrus.Fields{
+						"panic": rec,
+						"stack": string(debug.Stack()),
+					}).Error("panic recovered")
+					http.Error(w, "Internal server error", http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
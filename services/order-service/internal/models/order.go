@@ -16,6 +16,22 @@ const (
 	OrderStatusDelivered  OrderStatus = "delivered"
 	OrderStatusCancelled  OrderStatus = "cancelled"
 	OrderStatusRefunded   OrderStatus = "refunded"
+	// OrderStatusPartiallyShipped means at least one item has shipped or been
+	// delivered but not every item has been delivered yet.
+	OrderStatusPartiallyShipped OrderStatus = "partially_shipped"
+)
+
+// OrderItemStatus tracks the fulfillment state of a single order item, independent
+// of its parent order's overall status.
+type OrderItemStatus string
+
+const (
+	ItemStatusPending     OrderItemStatus = "pending"
+	ItemStatusAllocated   OrderItemStatus = "allocated"
+	ItemStatusShipped     OrderItemStatus = "shipped"
+	ItemStatusDelivered   OrderItemStatus = "delivered"
+	ItemStatusBackordered OrderItemStatus = "backordered"
+	ItemStatusRefunded    OrderItemStatus = "refunded"
 )
 
 // Order represents an order in the system
@@ -34,12 +50,23 @@ type Order struct {
 
 // OrderItem represents an item in an order
 type OrderItem struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	OrderID   uuid.UUID `json:"order_id" db:"order_id"`
-	ProductID uuid.UUID `json:"product_id" db:"product_id"`
-	Quantity  int       `json:"quantity" db:"quantity"`
-	UnitPrice float64   `json:"unit_price" db:"unit_price"`
-	TotalPrice float64  `json:"total_price" db:"total_price"`
+	ID               uuid.UUID       `json:"id" db:"id"`
+	OrderID          uuid.UUID       `json:"order_id" db:"order_id"`
+	ProductID        uuid.UUID       `json:"product_id" db:"product_id"`
+	Quantity         int             `json:"quantity" db:"quantity"`
+	UnitPrice        float64         `json:"unit_price" db:"unit_price"`
+	TotalPrice       float64         `json:"total_price" db:"total_price"`
+	Status           OrderItemStatus `json:"status" db:"status"`
+	QuantityShipped  int             `json:"quantity_shipped" db:"quantity_shipped"`
+	QuantityRefunded int             `json:"quantity_refunded" db:"quantity_refunded"`
+}
+
+// ItemFulfillment describes a warehouse update for a single order item: how many
+// units shipped (if any) and the item's new status.
+type ItemFulfillment struct {
+	ItemID          uuid.UUID       `json:"item_id" validate:"required"`
+	Status          OrderItemStatus `json:"status" validate:"required"`
+	QuantityShipped int             `json:"quantity_shipped"`
 }
 
 // Address represents a shipping or billing address
@@ -72,6 +99,16 @@ type UpdateOrderStatusRequest struct {
 	Status OrderStatus `json:"status" validate:"required"`
 }
 
+// FulfillItemsRequest represents a batch of per-item fulfillment updates for an order.
+type FulfillItemsRequest struct {
+	Items []ItemFulfillment `json:"items" validate:"required,min=1"`
+}
+
+// RefundItemRequest represents a request to refund (all or part of) an order item.
+type RefundItemRequest struct {
+	Quantity int `json:"quantity" validate:"required,min=1"`
+}
+
 // OrderResponse represents the response format for order operations
 type OrderResponse struct {
 	Order   *Order `json:"order"`
@@ -85,3 +122,94 @@ type OrdersResponse struct {
 	Page   int     `json:"page"`
 	Limit  int     `json:"limit"`
 }
+
+// OrderEventType identifies the kind of order lifecycle transition an OrderEvent records.
+type OrderEventType string
+
+const (
+	OrderEventCreated       OrderEventType = "order.created"
+	OrderEventStatusChanged OrderEventType = "order.status_changed"
+	OrderEventConfirmed     OrderEventType = "order.confirmed"
+	OrderEventShipped       OrderEventType = "order.shipped"
+	OrderEventCancelled     OrderEventType = "order.cancelled"
+	OrderEventFulfilled     OrderEventType = "order.fulfilled"
+	OrderEventRefunded      OrderEventType = "order.refunded"
+)
+
+// OrderEvent describes a single order lifecycle transition. Sequence increases
+// monotonically per order so consumers can detect gaps or out-of-order delivery.
+type OrderEvent struct {
+	Type           OrderEventType `json:"type"`
+	OrderID        uuid.UUID      `json:"order_id"`
+	UserID         uuid.UUID      `json:"user_id"`
+	PreviousStatus OrderStatus    `json:"previous_status,omitempty"`
+	NewStatus      OrderStatus    `json:"new_status"`
+	TotalAmount    float64        `json:"total_amount"`
+	Sequence       int64          `json:"sequence"`
+	OccurredAt     time.Time      `json:"occurred_at"`
+}
+
+// OutboxEvent wraps an OrderEvent with the bookkeeping fields needed to relay it
+// from the order_outbox table to an external publisher at-least-once.
+type OutboxEvent struct {
+	ID    uuid.UUID  `json:"id"`
+	Event OrderEvent `json:"event"`
+}
+
+// ReservationState tracks the lifecycle of a single stock reservation held against
+// an order item.
+type ReservationState string
+
+const (
+	ReservationStatePending   ReservationState = "pending"
+	ReservationStateConfirmed ReservationState = "confirmed"
+	ReservationStateReleased  ReservationState = "released"
+	ReservationStateExpired   ReservationState = "expired"
+)
+
+// SearchFilter narrows an admin order search. Zero-value fields are treated as
+// "don't filter on this"; Cursor/Limit drive keyset pagination.
+type SearchFilter struct {
+	Statuses    []OrderStatus
+	UserID      *uuid.UUID
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MinTotal    *float64
+	MaxTotal    *float64
+	Currency    string
+	Cursor      string
+	Limit       int
+}
+
+// OrderStats summarizes order volume and revenue for the admin dashboard.
+type OrderStats struct {
+	TotalOrders     int     `json:"total_orders"`
+	PendingOrders   int     `json:"pending_orders"`
+	CompletedOrders int     `json:"completed_orders"`
+	CancelledOrders int     `json:"cancelled_orders"`
+	TotalRevenue    float64 `json:"total_revenue"`
+}
+
+// OrderStatusEvent is the live notification broadcast to WebSocket subscribers (via
+// Redis Pub/Sub) when an order's status changes. Unlike OrderEvent/OutboxEvent it is
+// fire-and-forget and carries no delivery guarantee or sequence number.
+type OrderStatusEvent struct {
+	OrderID    uuid.UUID   `json:"order_id"`
+	UserID     uuid.UUID   `json:"user_id"`
+	OldStatus  OrderStatus `json:"old_status"`
+	NewStatus  OrderStatus `json:"new_status"`
+	OccurredAt time.Time   `json:"ts"`
+}
+
+// OrderReservation records a stock hold placed against a product for an order item,
+// so it can be released if the order fails to complete or is cancelled.
+type OrderReservation struct {
+	ID            uuid.UUID        `json:"id" db:"id"`
+	OrderID       uuid.UUID        `json:"order_id" db:"order_id"`
+	ProductID     uuid.UUID        `json:"product_id" db:"product_id"`
+	Quantity      int              `json:"quantity" db:"quantity"`
+	ReservationID string           `json:"reservation_id" db:"reservation_id"`
+	State         ReservationState `json:"state" db:"state"`
+	ExpiresAt     time.Time        `json:"expires_at" db:"expires_at"`
+	CreatedAt     time.Time        `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookSubscription is a registered HTTP callback that should receive a POST for
+// every order lifecycle event in Events. A nil UserID means the subscription is
+// global (e.g. an internal integration) rather than scoped to one user's orders.
+type WebhookSubscription struct {
+	ID        uuid.UUID        `json:"id" db:"id"`
+	UserID    *uuid.UUID       `json:"user_id,omitempty" db:"user_id"`
+	URL       string           `json:"url" db:"url"`
+	Secret    string           `json:"-" db:"secret"`
+	Events    []OrderEventType `json:"events" db:"events"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// CreateWebhookRequest represents a request to register a new webhook subscription.
+type CreateWebhookRequest struct {
+	UserID *uuid.UUID       `json:"user_id,omitempty"`
+	URL    string           `json:"url" validate:"required,url"`
+	Secret string           `json:"secret" validate:"required"`
+	Events []OrderEventType `json:"events" validate:"required,min=1"`
+}
+
+// UpdateWebhookRequest represents a request to change a webhook subscription's
+// endpoint, secret, or subscribed event types.
+type UpdateWebhookRequest struct {
+	URL    string           `json:"url" validate:"required,url"`
+	Secret string           `json:"secret" validate:"required"`
+	Events []OrderEventType `json:"events" validate:"required,min=1"`
+}
+
+// WebhookDelivery is a single queued attempt to deliver an order event to a webhook
+// subscription, tracked in the webhook_outbox table so retries survive a process
+// restart. Once Attempts reaches the configured max, the delivery is left undelivered
+// with DeadLettered set, rather than retried forever.
+type WebhookDelivery struct {
+	ID             uuid.UUID      `json:"id" db:"id"`
+	SubscriptionID uuid.UUID      `json:"subscription_id" db:"subscription_id"`
+	EventType      OrderEventType `json:"event_type" db:"event_type"`
+	Payload        []byte         `json:"-" db:"payload"`
+	Attempts       int            `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at" db:"next_attempt_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty" db:"delivered_at"`
+	DeadLettered   bool           `json:"dead_lettered" db:"dead_lettered"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+}
@@ -3,8 +3,11 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -12,72 +15,196 @@ import (
 	"github.com/jondmarien/eco-market/services/order-service/internal/models"
 )
 
+const (
+	// idempotencyPendingValue marks a claimed-but-not-yet-resolved idempotency key.
+	idempotencyPendingValue = "pending"
+	// idempotencyEntrySeparator joins the request hash and state packed into a single
+	// idempotency cache value; neither a hex sha256 digest nor "pending"/a UUID can
+	// contain it.
+	idempotencyEntrySeparator = ":"
+	// idempotencyLockTTL bounds how long a claim can block retries before it is
+	// considered abandoned (e.g. the original request's process crashed).
+	idempotencyLockTTL = 10 * time.Second
+)
+
+// Executor is the subset of *sql.DB and *sql.Tx that write methods need, so they can
+// run unmodified whether or not they're part of a larger WithTx composition. It's
+// exported so collaborators that must execute inside the same transaction as an order
+// write (e.g. the webhook outbox enqueuer) can accept it without depending on *sql.Tx.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// WebhookEnqueuer fans a status-change event out to any matching webhook
+// subscriptions inside the same transaction as the order write, so a committed order
+// transition can never be missed by the webhook delivery worker.
+type WebhookEnqueuer interface {
+	EnqueueForEvent(ctx context.Context, executor Executor, event models.OrderEvent) error
+}
+
 // OrderRepository handles database operations for orders
 type OrderRepository struct {
-	db    *sql.DB
-	redis *redis.Client
+	db              *sql.DB
+	redis           *redis.Client
+	executor        Executor
+	webhookEnqueuer WebhookEnqueuer
+	// inTx and afterCommit are only set on the repository handed to a WithTx
+	// callback; afterCommit queues side effects (cache writes/invalidations) that
+	// must not run unless the outermost transaction actually commits.
+	inTx        bool
+	afterCommit *[]func()
 }
 
-// NewOrderRepository creates a new OrderRepository
-func NewOrderRepository(db *sql.DB, redis *redis.Client) *OrderRepository {
+// NewOrderRepository creates a new OrderRepository. webhookEnqueuer may be nil if the
+// webhook subsystem isn't wired up.
+func NewOrderRepository(db *sql.DB, redis *redis.Client, webhookEnqueuer WebhookEnqueuer) *OrderRepository {
 	return &OrderRepository{
-		db:    db,
-		redis: redis,
+		db:              db,
+		redis:           redis,
+		executor:        db,
+		webhookEnqueuer: webhookEnqueuer,
 	}
 }
 
-// CreateOrder creates a new order in the database
-func (r *OrderRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+// WithTx runs fn against a repository bound to a single transaction, committing if fn
+// returns nil and rolling back otherwise. This lets callers compose several write
+// methods (e.g. UpdateOrderStatus + a refund insert) into one atomic operation. If r
+// is already inside a WithTx call, fn reuses that transaction instead of nesting one.
+func (r *OrderRepository) WithTx(ctx context.Context, fn func(txRepo *OrderRepository) error) error {
+	if r.inTx {
+		return fn(r)
+	}
+
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	// Insert order
-	query := `
-		INSERT INTO orders (
-			id, user_id, status, total_amount, currency,
-			shipping_street, shipping_city, shipping_state, shipping_postal_code, shipping_country,
-			billing_street, billing_city, billing_state, billing_postal_code, billing_country,
-			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
-
-	_, err = tx.ExecContext(ctx, query,
-		order.ID, order.UserID, order.Status, order.TotalAmount, order.Currency,
-		order.ShippingAddress.Street, order.ShippingAddress.City, order.ShippingAddress.State,
-		order.ShippingAddress.PostalCode, order.ShippingAddress.Country,
-		order.BillingAddress.Street, order.BillingAddress.City, order.BillingAddress.State,
-		order.BillingAddress.PostalCode, order.BillingAddress.Country,
-		order.CreatedAt, order.UpdatedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to insert order: %w", err)
+	hooks := &[]func(){}
+	txRepo := &OrderRepository{db: r.db, redis: r.redis, executor: tx, webhookEnqueuer: r.webhookEnqueuer, inTx: true, afterCommit: hooks}
+
+	if err := fn(txRepo); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// Insert order items
-	for _, item := range order.Items {
-		itemQuery := `
-			INSERT INTO order_items (id, order_id, product_id, quantity, unit_price, total_price)
-			VALUES ($1, $2, $3, $4, $5, $6)`
+	for _, hook := range *hooks {
+		hook()
+	}
+
+	return nil
+}
 
-		_, err = tx.ExecContext(ctx, itemQuery,
-			item.ID, item.OrderID, item.ProductID, item.Quantity, item.UnitPrice, item.TotalPrice,
+// enqueueWebhooks fans event out to any matching webhook subscriptions via
+// r.webhookEnqueuer, inside the same transaction as the order write. It's a no-op if
+// no enqueuer was wired up (i.e. the webhook subsystem isn't enabled).
+func (r *OrderRepository) enqueueWebhooks(ctx context.Context, event models.OrderEvent) error {
+	if r.webhookEnqueuer == nil {
+		return nil
+	}
+	if err := r.webhookEnqueuer.EnqueueForEvent(ctx, r.executor, event); err != nil {
+		return fmt.Errorf("failed to enqueue webhook event: %w", err)
+	}
+	return nil
+}
+
+// runAfterCommit defers a side effect (cache write/invalidation) until the enclosing
+// transaction commits, or runs it immediately if there is no enclosing transaction.
+func (r *OrderRepository) runAfterCommit(fn func()) {
+	if r.afterCommit != nil {
+		*r.afterCommit = append(*r.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+// CreateOrder creates a new order, its items, and the stock reservations held for
+// it in a single transaction, so an order is never persisted without the holds that
+// back it.
+func (r *OrderRepository) CreateOrder(ctx context.Context, order *models.Order, reservations []models.OrderReservation) error {
+	return r.WithTx(ctx, func(txRepo *OrderRepository) error {
+		// Insert order
+		query := `
+			INSERT INTO orders (
+				id, user_id, status, total_amount, currency,
+				shipping_street, shipping_city, shipping_state, shipping_postal_code, shipping_country,
+				billing_street, billing_city, billing_state, billing_postal_code, billing_country,
+				created_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+
+		_, err := txRepo.executor.ExecContext(ctx, query,
+			order.ID, order.UserID, order.Status, order.TotalAmount, order.Currency,
+			order.ShippingAddress.Street, order.ShippingAddress.City, order.ShippingAddress.State,
+			order.ShippingAddress.PostalCode, order.ShippingAddress.Country,
+			order.BillingAddress.Street, order.BillingAddress.City, order.BillingAddress.State,
+			order.BillingAddress.PostalCode, order.BillingAddress.Country,
+			order.CreatedAt, order.UpdatedAt,
 		)
 		if err != nil {
-			return fmt.Errorf("failed to insert order item: %w", err)
+			return fmt.Errorf("failed to insert order: %w", err)
 		}
-	}
 
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
+		// Insert order items
+		for _, item := range order.Items {
+			if item.Status == "" {
+				item.Status = models.ItemStatusPending
+			}
 
-	// Cache the order in Redis
-	r.cacheOrder(ctx, order)
+			itemQuery := `
+				INSERT INTO order_items (id, order_id, product_id, quantity, unit_price, total_price, status, quantity_shipped, quantity_refunded)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 
-	return nil
+			_, err = txRepo.executor.ExecContext(ctx, itemQuery,
+				item.ID, item.OrderID, item.ProductID, item.Quantity, item.UnitPrice, item.TotalPrice,
+				item.Status, item.QuantityShipped, item.QuantityRefunded,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert order item: %w", err)
+			}
+		}
+
+		for _, reservation := range reservations {
+			reservationQuery := `
+				INSERT INTO order_reservations (id, order_id, product_id, quantity, reservation_id, state, expires_at, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+			_, err = txRepo.executor.ExecContext(ctx, reservationQuery,
+				reservation.ID, reservation.OrderID, reservation.ProductID, reservation.Quantity,
+				reservation.ReservationID, reservation.State, reservation.ExpiresAt, reservation.CreatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to insert order reservation: %w", err)
+			}
+		}
+
+		// Record the creation event in the outbox in the same transaction as the order
+		// write, so a consumer is guaranteed to eventually see it even if the relay or
+		// the broker is down when the order is created.
+		event := models.OrderEvent{
+			Type:        models.OrderEventCreated,
+			OrderID:     order.ID,
+			UserID:      order.UserID,
+			NewStatus:   order.Status,
+			TotalAmount: order.TotalAmount,
+			OccurredAt:  order.CreatedAt,
+		}
+		if err := txRepo.insertOutboxEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to record order event: %w", err)
+		}
+		if err := txRepo.enqueueWebhooks(ctx, event); err != nil {
+			return err
+		}
+
+		txRepo.runAfterCommit(func() { r.cacheOrder(ctx, order) })
+
+		return nil
+	})
 }
 
 // GetOrderByID retrieves an order by ID
@@ -181,59 +308,407 @@ func (r *OrderRepository) GetOrdersByUserID(ctx context.Context, userID uuid.UUI
 	return orders, total, nil
 }
 
-// UpdateOrderStatus updates the status of an order
-func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, status models.OrderStatus) error {
-	query := "UPDATE orders SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2"
-	result, err := r.db.ExecContext(ctx, query, status, orderID)
+// SearchOrders runs an admin search over orders with optional status/user/date/amount
+// filters and keyset pagination, returning the matching page and an opaque cursor for
+// the next page (empty once there are no more results). Keyset pagination is used
+// instead of OFFSET so paging stays fast even over millions of orders.
+func (r *OrderRepository) SearchOrders(ctx context.Context, filter models.SearchFilter) ([]models.Order, string, error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	conditions := []string{"1=1"}
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			placeholders[i] = arg(status)
+		}
+		conditions = append(conditions, fmt.Sprintf("status IN (%s)", strings.Join(placeholders, ", ")))
+	}
+	if filter.UserID != nil {
+		conditions = append(conditions, "user_id = "+arg(*filter.UserID))
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "created_at <= "+arg(*filter.CreatedTo))
+	}
+	if filter.MinTotal != nil {
+		conditions = append(conditions, "total_amount >= "+arg(*filter.MinTotal))
+	}
+	if filter.MaxTotal != nil {
+		conditions = append(conditions, "total_amount <= "+arg(*filter.MaxTotal))
+	}
+	if filter.Currency != "" {
+		conditions = append(conditions, "currency = "+arg(filter.Currency))
+	}
+
+	if filter.Cursor != "" {
+		cursorTime, cursorID, err := decodeSearchCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid cursor: %w", err)
+		}
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < (%s, %s)", arg(cursorTime), arg(cursorID)))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, status, total_amount, currency,
+			   shipping_street, shipping_city, shipping_state, shipping_postal_code, shipping_country,
+			   billing_street, billing_city, billing_state, billing_postal_code, billing_country,
+			   created_at, updated_at
+		FROM orders
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT %s`, strings.Join(conditions, " AND "), arg(limit+1))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to search orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []models.Order
+	for rows.Next() {
+		var order models.Order
+		err := rows.Scan(
+			&order.ID, &order.UserID, &order.Status, &order.TotalAmount, &order.Currency,
+			&order.ShippingAddress.Street, &order.ShippingAddress.City, &order.ShippingAddress.State,
+			&order.ShippingAddress.PostalCode, &order.ShippingAddress.Country,
+			&order.BillingAddress.Street, &order.BillingAddress.City, &order.BillingAddress.State,
+			&order.BillingAddress.PostalCode, &order.BillingAddress.Country,
+			&order.CreatedAt, &order.UpdatedAt,
+		)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+
+	nextCursor := ""
+	if len(orders) > limit {
+		last := orders[limit-1]
+		nextCursor = encodeSearchCursor(last.CreatedAt, last.ID)
+		orders = orders[:limit]
+	}
+
+	return orders, nextCursor, nil
+}
+
+// encodeSearchCursor packs a keyset position into an opaque, URL-safe cursor string.
+func encodeSearchCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := fmt.Sprintf("%d|%s", createdAt.UnixNano(), id.String())
+	return base64.URLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSearchCursor reverses encodeSearchCursor.
+func decodeSearchCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.URLEncoding.DecodeString(cursor)
 	if err != nil {
-		return fmt.Errorf("failed to update order status: %w", err)
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("failed to decode cursor: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("malformed cursor")
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("malformed cursor timestamp: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("order not found")
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("malformed cursor id: %w", err)
 	}
 
-	// Invalidate cache
-	r.invalidateOrderCache(ctx, orderID)
+	return time.Unix(0, nanos), id, nil
+}
 
-	return nil
+// GetOrderStats aggregates order counts and revenue for the admin dashboard.
+func (r *OrderRepository) GetOrderStats(ctx context.Context) (*models.OrderStats, error) {
+	stats := &models.OrderStats{}
+
+	countQuery := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'pending'),
+			COUNT(*) FILTER (WHERE status = 'delivered'),
+			COUNT(*) FILTER (WHERE status = 'cancelled')
+		FROM orders`
+
+	row := r.db.QueryRowContext(ctx, countQuery)
+	if err := row.Scan(&stats.TotalOrders, &stats.PendingOrders, &stats.CompletedOrders, &stats.CancelledOrders); err != nil {
+		return nil, fmt.Errorf("failed to aggregate order counts: %w", err)
+	}
+
+	revenueQuery := "SELECT COALESCE(SUM(total_amount), 0) FROM orders WHERE status NOT IN ('cancelled', 'refunded')"
+	if err := r.db.QueryRowContext(ctx, revenueQuery).Scan(&stats.TotalRevenue); err != nil {
+		return nil, fmt.Errorf("failed to aggregate order revenue: %w", err)
+	}
+
+	return stats, nil
 }
 
-// DeleteOrder deletes an order (only if pending)
-func (r *OrderRepository) DeleteOrder(ctx context.Context, orderID uuid.UUID) error {
-	query := "DELETE FROM orders WHERE id = $1 AND status = 'pending'"
-	result, err := r.db.ExecContext(ctx, query, orderID)
+// UpdateOrderStatus updates the status of an order and records the transition in the
+// outbox, in the same transaction, so the event can't be lost relative to the write.
+func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, status models.OrderStatus) error {
+	return r.WithTx(ctx, func(txRepo *OrderRepository) error {
+		var previousStatus models.OrderStatus
+		var userID uuid.UUID
+		var totalAmount float64
+		row := txRepo.executor.QueryRowContext(ctx, "SELECT status, user_id, total_amount FROM orders WHERE id = $1 FOR UPDATE", orderID)
+		if err := row.Scan(&previousStatus, &userID, &totalAmount); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("order not found")
+			}
+			return fmt.Errorf("failed to get order for status update: %w", err)
+		}
+
+		query := "UPDATE orders SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2"
+		if _, err := txRepo.executor.ExecContext(ctx, query, status, orderID); err != nil {
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+
+		event := models.OrderEvent{
+			Type:           eventTypeForStatusChange(status),
+			OrderID:        orderID,
+			UserID:         userID,
+			PreviousStatus: previousStatus,
+			NewStatus:      status,
+			TotalAmount:    totalAmount,
+			OccurredAt:     time.Now(),
+		}
+		if err := txRepo.insertOutboxEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to record order event: %w", err)
+		}
+		if err := txRepo.enqueueWebhooks(ctx, event); err != nil {
+			return err
+		}
+
+		txRepo.runAfterCommit(func() { r.invalidateOrderCache(ctx, orderID) })
+
+		return nil
+	})
+}
+
+// eventTypeForStatusChange picks the most specific OrderEventType for a transition,
+// falling back to the generic status-changed event for statuses webhook subscribers
+// don't need to filter on individually (processing, partially_shipped).
+func eventTypeForStatusChange(newStatus models.OrderStatus) models.OrderEventType {
+	switch newStatus {
+	case models.OrderStatusConfirmed:
+		return models.OrderEventConfirmed
+	case models.OrderStatusShipped:
+		return models.OrderEventShipped
+	case models.OrderStatusCancelled:
+		return models.OrderEventCancelled
+	case models.OrderStatusDelivered:
+		return models.OrderEventFulfilled
+	case models.OrderStatusRefunded:
+		return models.OrderEventRefunded
+	default:
+		return models.OrderEventStatusChanged
+	}
+}
+
+// FulfillItems atomically applies a batch of per-item status/shipped-quantity updates
+// and derives the parent order's status from the resulting item states: every item
+// delivered moves the order to delivered, a shipped item alongside a pending one moves
+// it to partially shipped. It returns the order's new status.
+func (r *OrderRepository) FulfillItems(ctx context.Context, orderID uuid.UUID, fulfillments []models.ItemFulfillment) (models.OrderStatus, error) {
+	var newStatus models.OrderStatus
+
+	err := r.WithTx(ctx, func(txRepo *OrderRepository) error {
+		for _, f := range fulfillments {
+			query := `
+				UPDATE order_items
+				SET status = $1, quantity_shipped = $2
+				WHERE id = $3 AND order_id = $4`
+
+			result, err := txRepo.executor.ExecContext(ctx, query, f.Status, f.QuantityShipped, f.ItemID, orderID)
+			if err != nil {
+				return fmt.Errorf("failed to update item %s: %w", f.ItemID, err)
+			}
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			if rowsAffected == 0 {
+				return fmt.Errorf("order item not found: %s", f.ItemID)
+			}
+		}
+
+		items, err := txRepo.getOrderItems(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to reload order items: %w", err)
+		}
+
+		newStatus = deriveOrderStatus(items)
+
+		var previousStatus models.OrderStatus
+		var userID uuid.UUID
+		var totalAmount float64
+		row := txRepo.executor.QueryRowContext(ctx, "SELECT status, user_id, total_amount FROM orders WHERE id = $1 FOR UPDATE", orderID)
+		if err := row.Scan(&previousStatus, &userID, &totalAmount); err != nil {
+			return fmt.Errorf("failed to load order for fulfillment: %w", err)
+		}
+
+		if newStatus == previousStatus {
+			return nil
+		}
+
+		if _, err := txRepo.executor.ExecContext(ctx, "UPDATE orders SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", newStatus, orderID); err != nil {
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+
+		event := models.OrderEvent{
+			Type:           eventTypeForStatusChange(newStatus),
+			OrderID:        orderID,
+			UserID:         userID,
+			PreviousStatus: previousStatus,
+			NewStatus:      newStatus,
+			TotalAmount:    totalAmount,
+			OccurredAt:     time.Now(),
+		}
+		if err := txRepo.insertOutboxEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to record order event: %w", err)
+		}
+		if err := txRepo.enqueueWebhooks(ctx, event); err != nil {
+			return err
+		}
+
+		txRepo.runAfterCommit(func() { r.invalidateOrderCache(ctx, orderID) })
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete order: %w", err)
+		return "", err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return newStatus, nil
+}
+
+// RefundItem records a (possibly partial) refund against an order item and returns
+// the order's remaining refundable amount afterwards.
+func (r *OrderRepository) RefundItem(ctx context.Context, orderID, itemID uuid.UUID, quantity int) (float64, error) {
+	var remainingRefundable float64
+
+	err := r.WithTx(ctx, func(txRepo *OrderRepository) error {
+		var itemQuantity, alreadyRefunded int
+		var unitPrice float64
+		row := txRepo.executor.QueryRowContext(ctx,
+			"SELECT quantity, quantity_refunded, unit_price FROM order_items WHERE id = $1 AND order_id = $2 FOR UPDATE",
+			itemID, orderID)
+		if err := row.Scan(&itemQuantity, &alreadyRefunded, &unitPrice); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("order item not found")
+			}
+			return fmt.Errorf("failed to load order item: %w", err)
+		}
+
+		if alreadyRefunded+quantity > itemQuantity {
+			return fmt.Errorf("refund quantity %d exceeds remaining refundable quantity %d", quantity, itemQuantity-alreadyRefunded)
+		}
+
+		newRefunded := alreadyRefunded + quantity
+		newStatus := models.OrderItemStatus("")
+		if newRefunded == itemQuantity {
+			newStatus = models.ItemStatusRefunded
+		}
+
+		var err error
+		if newStatus != "" {
+			_, err = txRepo.executor.ExecContext(ctx, "UPDATE order_items SET quantity_refunded = $1, status = $2 WHERE id = $3", newRefunded, newStatus, itemID)
+		} else {
+			_, err = txRepo.executor.ExecContext(ctx, "UPDATE order_items SET quantity_refunded = $1 WHERE id = $2", newRefunded, itemID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update order item refund: %w", err)
+		}
+
+		sumQuery := `
+			SELECT COALESCE(SUM(unit_price * (quantity - quantity_refunded)), 0)
+			FROM order_items WHERE order_id = $1`
+		if err := txRepo.executor.QueryRowContext(ctx, sumQuery, orderID).Scan(&remainingRefundable); err != nil {
+			return fmt.Errorf("failed to compute remaining refundable amount: %w", err)
+		}
+
+		txRepo.runAfterCommit(func() { r.invalidateOrderCache(ctx, orderID) })
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return 0, err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("order not found or cannot be deleted")
+	return remainingRefundable, nil
+}
+
+// deriveOrderStatus computes an order's overall status from its items: all delivered
+// wins outright, any shipped alongside a non-delivered item means partially shipped.
+func deriveOrderStatus(items []models.OrderItem) models.OrderStatus {
+	allDelivered := len(items) > 0
+	anyShippedOrDelivered := false
+
+	for _, item := range items {
+		if item.Status != models.ItemStatusDelivered {
+			allDelivered = false
+		}
+		if item.Status == models.ItemStatusShipped || item.Status == models.ItemStatusDelivered {
+			anyShippedOrDelivered = true
+		}
 	}
 
-	// Invalidate cache
-	r.invalidateOrderCache(ctx, orderID)
+	if allDelivered {
+		return models.OrderStatusDelivered
+	}
+	if anyShippedOrDelivered {
+		return models.OrderStatusPartiallyShipped
+	}
+	return models.OrderStatusProcessing
+}
 
-	return nil
+// DeleteOrder deletes an order (only if pending)
+func (r *OrderRepository) DeleteOrder(ctx context.Context, orderID uuid.UUID) error {
+	return r.WithTx(ctx, func(txRepo *OrderRepository) error {
+		query := "DELETE FROM orders WHERE id = $1 AND status = 'pending'"
+		result, err := txRepo.executor.ExecContext(ctx, query, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to delete order: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("order not found or cannot be deleted")
+		}
+
+		txRepo.runAfterCommit(func() { r.invalidateOrderCache(ctx, orderID) })
+
+		return nil
+	})
 }
 
-// getOrderItems retrieves items for a specific order
+// getOrderItems retrieves items for a specific order, using r.executor so it sees
+// uncommitted writes when called as part of a larger WithTx composition.
 func (r *OrderRepository) getOrderItems(ctx context.Context, orderID uuid.UUID) ([]models.OrderItem, error) {
 	query := `
-		SELECT id, order_id, product_id, quantity, unit_price, total_price
+		SELECT id, order_id, product_id, quantity, unit_price, total_price, status, quantity_shipped, quantity_refunded
 		FROM order_items WHERE order_id = $1`
 
-	rows, err := r.db.QueryContext(ctx, query, orderID)
+	rows, err := r.executor.QueryContext(ctx, query, orderID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query order items: %w", err)
 	}
@@ -245,6 +720,7 @@ func (r *OrderRepository) getOrderItems(ctx context.Context, orderID uuid.UUID)
 		err := rows.Scan(
 			&item.ID, &item.OrderID, &item.ProductID,
 			&item.Quantity, &item.UnitPrice, &item.TotalPrice,
+			&item.Status, &item.QuantityShipped, &item.QuantityRefunded,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan order item: %w", err)
@@ -288,6 +764,227 @@ func (r *OrderRepository) invalidateOrderCache(ctx context.Context, orderID uuid
 	r.redis.Del(ctx, cacheKey)
 }
 
+// AcquireIdempotencyLock attempts to atomically claim an idempotency key for a user.
+// It uses a Redis SETNX-with-TTL so that a retried request (same key) observes either
+// an in-flight placeholder or the ID of the order that the original request created.
+// requestHash pins the claim to the request body that made it (typically
+// sha256(userID|key|body)): a retry with the same key but a different hash is a
+// client bug (key reuse across distinct requests) and is reported as conflict rather
+// than silently replayed. locked is true only when this call is the one that claimed
+// the key.
+func (r *OrderRepository) AcquireIdempotencyLock(ctx context.Context, key string, userID uuid.UUID, requestHash string) (locked bool, existingOrderID *uuid.UUID, conflict bool, err error) {
+	redisKey := idempotencyCacheKey(userID, key)
+
+	ok, err := r.redis.SetNX(ctx, redisKey, idempotencyEntryValue(requestHash, idempotencyPendingValue), idempotencyLockTTL).Result()
+	if err != nil {
+		return false, nil, false, fmt.Errorf("failed to acquire idempotency lock: %w", err)
+	}
+	if ok {
+		return true, nil, false, nil
+	}
+
+	// Someone else already holds (or held) this key; find out what state it's in.
+	val, err := r.redis.Get(ctx, redisKey).Result()
+	if err != nil {
+		if err == redis.Nil {
+			// Lock expired between the SETNX and the GET; safe to retry the claim.
+			return r.AcquireIdempotencyLock(ctx, key, userID, requestHash)
+		}
+		return false, nil, false, fmt.Errorf("failed to inspect idempotency key: %w", err)
+	}
+
+	storedHash, state, err := splitIdempotencyEntry(val)
+	if err != nil {
+		return false, nil, false, err
+	}
+	if storedHash != requestHash {
+		return false, nil, true, nil
+	}
+
+	if state == idempotencyPendingValue {
+		return false, nil, false, nil
+	}
+
+	orderID, err := uuid.Parse(state)
+	if err != nil {
+		return false, nil, false, fmt.Errorf("failed to parse cached idempotency order id: %w", err)
+	}
+
+	return false, &orderID, false, nil
+}
+
+// RecordIdempotencyResult overwrites a claimed idempotency key with the ID of the order
+// it produced, extending its TTL so later retries can be answered without re-running
+// CreateOrder.
+func (r *OrderRepository) RecordIdempotencyResult(ctx context.Context, key string, userID, orderID uuid.UUID, requestHash string, ttl time.Duration) error {
+	redisKey := idempotencyCacheKey(userID, key)
+	if err := r.redis.Set(ctx, redisKey, idempotencyEntryValue(requestHash, orderID.String()), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to record idempotency result: %w", err)
+	}
+	return nil
+}
+
+// idempotencyEntryValue packs the request hash and state (idempotencyPendingValue or
+// an order ID) that back a single idempotency key into the one string Redis stores.
+func idempotencyEntryValue(requestHash, state string) string {
+	return requestHash + idempotencyEntrySeparator + state
+}
+
+// splitIdempotencyEntry unpacks a value written by idempotencyEntryValue.
+func splitIdempotencyEntry(val string) (requestHash, state string, err error) {
+	parts := strings.SplitN(val, idempotencyEntrySeparator, 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("malformed idempotency cache entry")
+	}
+	return parts[0], parts[1], nil
+}
+
+func idempotencyCacheKey(userID uuid.UUID, key string) string {
+	return fmt.Sprintf("idempotency:%s:%s", userID.String(), key)
+}
+
+// insertOutboxEvent writes an order event into order_outbox via r.executor, assigning
+// it the next sequence number for its order. Callers composing an atomic operation
+// should call this on the txRepo passed into WithTx so the insert shares that
+// transaction.
+func (r *OrderRepository) insertOutboxEvent(ctx context.Context, event models.OrderEvent) error {
+	var sequence int64
+	seqQuery := "SELECT COALESCE(MAX(sequence), 0) + 1 FROM order_outbox WHERE order_id = $1"
+	if err := r.executor.QueryRowContext(ctx, seqQuery, event.OrderID).Scan(&sequence); err != nil {
+		return fmt.Errorf("failed to compute outbox sequence: %w", err)
+	}
+	event.Sequence = sequence
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order event: %w", err)
+	}
+
+	query := `
+		INSERT INTO order_outbox (id, order_id, event_type, sequence, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err = r.executor.ExecContext(ctx, query, uuid.New(), event.OrderID, event.Type, sequence, payload, event.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert outbox event: %w", err)
+	}
+
+	return nil
+}
+
+// FetchUnpublishedOutboxEvents returns up to limit outbox rows that haven't been
+// delivered yet, oldest first, for the relay to publish.
+func (r *OrderRepository) FetchUnpublishedOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	query := `
+		SELECT id, payload
+		FROM order_outbox
+		WHERE delivered_at IS NULL
+		ORDER BY created_at ASC
+		LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var outboxEvents []models.OutboxEvent
+	for rows.Next() {
+		var id uuid.UUID
+		var payload []byte
+		if err := rows.Scan(&id, &payload); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+
+		var event models.OrderEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal outbox event: %w", err)
+		}
+
+		outboxEvents = append(outboxEvents, models.OutboxEvent{ID: id, Event: event})
+	}
+
+	return outboxEvents, nil
+}
+
+// MarkOutboxEventDelivered records that an outbox event was successfully published.
+func (r *OrderRepository) MarkOutboxEventDelivered(ctx context.Context, id uuid.UUID) error {
+	query := "UPDATE order_outbox SET delivered_at = CURRENT_TIMESTAMP WHERE id = $1"
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark outbox event delivered: %w", err)
+	}
+	return nil
+}
+
+// GetReservationsByOrderID returns all stock reservations held for an order, e.g. so
+// CancelOrder can release each one.
+func (r *OrderRepository) GetReservationsByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderReservation, error) {
+	query := `
+		SELECT id, order_id, product_id, quantity, reservation_id, state, expires_at, created_at
+		FROM order_reservations WHERE order_id = $1`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order reservations: %w", err)
+	}
+	defer rows.Close()
+
+	var reservations []models.OrderReservation
+	for rows.Next() {
+		var reservation models.OrderReservation
+		err := rows.Scan(
+			&reservation.ID, &reservation.OrderID, &reservation.ProductID, &reservation.Quantity,
+			&reservation.ReservationID, &reservation.State, &reservation.ExpiresAt, &reservation.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order reservation: %w", err)
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, nil
+}
+
+// UpdateReservationState transitions a reservation to a new state (confirmed, released, expired).
+func (r *OrderRepository) UpdateReservationState(ctx context.Context, id uuid.UUID, state models.ReservationState) error {
+	query := "UPDATE order_reservations SET state = $1 WHERE id = $2"
+	if _, err := r.db.ExecContext(ctx, query, state, id); err != nil {
+		return fmt.Errorf("failed to update reservation state: %w", err)
+	}
+	return nil
+}
+
+// ExpireStaleReservations flags still-pending reservations past their expiry as expired
+// and returns them so the caller can release the matching holds with the StockReserver.
+func (r *OrderRepository) ExpireStaleReservations(ctx context.Context, now time.Time) ([]models.OrderReservation, error) {
+	query := `
+		UPDATE order_reservations
+		SET state = $1
+		WHERE state = $2 AND expires_at < $3
+		RETURNING id, order_id, product_id, quantity, reservation_id, state, expires_at, created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, models.ReservationStateExpired, models.ReservationStatePending, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire stale reservations: %w", err)
+	}
+	defer rows.Close()
+
+	var reservations []models.OrderReservation
+	for rows.Next() {
+		var reservation models.OrderReservation
+		err := rows.Scan(
+			&reservation.ID, &reservation.OrderID, &reservation.ProductID, &reservation.Quantity,
+			&reservation.ReservationID, &reservation.State, &reservation.ExpiresAt, &reservation.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expired reservation: %w", err)
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, nil
+}
+
 // InitializeDatabase creates the database schema
 func (r *OrderRepository) InitializeDatabase(ctx context.Context) error {
 	schema := `
@@ -318,14 +1015,41 @@ func (r *OrderRepository) InitializeDatabase(ctx context.Context) error {
 		quantity INTEGER NOT NULL CHECK (quantity > 0),
 		unit_price DECIMAL(10,2) NOT NULL CHECK (unit_price >= 0),
 		total_price DECIMAL(10,2) NOT NULL CHECK (total_price >= 0),
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		quantity_shipped INTEGER NOT NULL DEFAULT 0 CHECK (quantity_shipped >= 0),
+		quantity_refunded INTEGER NOT NULL DEFAULT 0 CHECK (quantity_refunded >= 0),
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS order_reservations (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+		product_id UUID NOT NULL,
+		quantity INTEGER NOT NULL CHECK (quantity > 0),
+		reservation_id VARCHAR(255) NOT NULL,
+		state VARCHAR(20) NOT NULL DEFAULT 'pending',
+		expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
 		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 	);
 
+	CREATE TABLE IF NOT EXISTS order_outbox (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+		event_type VARCHAR(50) NOT NULL,
+		sequence BIGINT NOT NULL,
+		payload JSONB NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		delivered_at TIMESTAMP WITH TIME ZONE
+	);
+
 	CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders(user_id);
 	CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status);
 	CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at);
 	CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id);
 	CREATE INDEX IF NOT EXISTS idx_order_items_product_id ON order_items(product_id);
+	CREATE INDEX IF NOT EXISTS idx_order_outbox_undelivered ON order_outbox(created_at) WHERE delivered_at IS NULL;
+	CREATE INDEX IF NOT EXISTS idx_order_reservations_order_id ON order_reservations(order_id);
+	CREATE INDEX IF NOT EXISTS idx_order_reservations_pending_expiry ON order_reservations(expires_at) WHERE state = 'pending';
 	`
 
 	_, err := r.db.ExecContext(ctx, schema)
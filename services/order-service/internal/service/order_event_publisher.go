@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+)
+
+// OrderEventPublisher broadcasts order status transitions to interested live
+// subscribers (e.g. a WebSocket stream fed by Redis Pub/Sub). It is distinct from
+// the transactional outbox: delivery is best-effort and carries no retry guarantee,
+// so it's only suited for push notifications, not for driving other services' state.
+type OrderEventPublisher interface {
+	PublishStatusChange(ctx context.Context, event models.OrderStatusEvent) error
+}
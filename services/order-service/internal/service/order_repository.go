@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+)
+
+// OrderRepository is the persistence boundary OrderService depends on. It's
+// satisfied by *repository.OrderRepository; declaring it here (rather than
+// depending on that concrete type) lets service-layer tests inject an in-memory
+// fake instead of a real Postgres/Redis connection.
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, order *models.Order, reservations []models.OrderReservation) error
+	GetOrderByID(ctx context.Context, orderID uuid.UUID) (*models.Order, error)
+	GetOrdersByUserID(ctx context.Context, userID uuid.UUID, page, limit int) ([]models.Order, int, error)
+	SearchOrders(ctx context.Context, filter models.SearchFilter) ([]models.Order, string, error)
+	GetOrderStats(ctx context.Context) (*models.OrderStats, error)
+	UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, status models.OrderStatus) error
+	FulfillItems(ctx context.Context, orderID uuid.UUID, fulfillments []models.ItemFulfillment) (models.OrderStatus, error)
+	RefundItem(ctx context.Context, orderID, itemID uuid.UUID, quantity int) (float64, error)
+
+	AcquireIdempotencyLock(ctx context.Context, key string, userID uuid.UUID, requestHash string) (locked bool, existingOrderID *uuid.UUID, conflict bool, err error)
+	RecordIdempotencyResult(ctx context.Context, key string, userID, orderID uuid.UUID, requestHash string, ttl time.Duration) error
+
+	GetReservationsByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderReservation, error)
+	UpdateReservationState(ctx context.Context, id uuid.UUID, state models.ReservationState) error
+	ExpireStaleReservations(ctx context.Context, now time.Time) ([]models.OrderReservation, error)
+}
@@ -2,31 +2,78 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jondmarien/eco-market/services/order-service/internal/models"
-	"github.com/jondmarien/eco-market/services/order-service/internal/repository"
 )
 
+// idempotencyResultTTL is how long a completed order stays retrievable by its
+// Idempotency-Key once CreateOrder has committed.
+const idempotencyResultTTL = 24 * time.Hour
+
+// reservationTTL bounds how long a stock hold can sit pending before the reaper
+// treats it as abandoned (e.g. the order that would have confirmed it never committed).
+const reservationTTL = 15 * time.Minute
+
+// ErrIdempotencyInFlight is returned when a request reuses an Idempotency-Key
+// whose original request hasn't finished yet.
+var ErrIdempotencyInFlight = errors.New("request with this idempotency key is still being processed")
+
+// ErrIdempotencyKeyConflict is returned when a request reuses an Idempotency-Key with
+// a different request body than the one that originally claimed it, which is a client
+// bug rather than a safe-to-replay retry.
+var ErrIdempotencyKeyConflict = errors.New("idempotency key was already used with a different request")
+
 // OrderService provides business logic for orders
 type OrderService struct {
-	repo *repository.OrderRepository
+	repo           OrderRepository
+	stockReserver  StockReserver
+	eventPublisher OrderEventPublisher
 }
 
 // NewOrderService creates a new OrderService
-func NewOrderService(repo *repository.OrderRepository) *OrderService {
+func NewOrderService(repo OrderRepository, stockReserver StockReserver, eventPublisher OrderEventPublisher) *OrderService {
 	return &OrderService{
-		repo: repo,
+		repo:           repo,
+		stockReserver:  stockReserver,
+		eventPublisher: eventPublisher,
 	}
 }
 
-// CreateOrder creates a new order with business logic validation
-func (s *OrderService) CreateOrder(ctx context.Context, req models.CreateOrderRequest) (*models.Order, error) {
+// CreateOrder creates a new order with business logic validation. When idempotencyKey
+// is non-empty, a retried request with the same key and requestHash (typically
+// sha256(userID|key|body), computed by the caller) returns the original order instead
+// of creating a duplicate; the returned bool reports whether the order was replayed
+// from a prior request rather than just created. Reusing the key with a different
+// requestHash fails with ErrIdempotencyKeyConflict rather than being replayed.
+func (s *OrderService) CreateOrder(ctx context.Context, req models.CreateOrderRequest, idempotencyKey, requestHash string) (*models.Order, bool, error) {
 	// Validate request
 	if len(req.Items) == 0 {
-		return nil, fmt.Errorf("order must contain at least one item")
+		return nil, false, fmt.Errorf("order must contain at least one item")
+	}
+
+	if idempotencyKey != "" {
+		locked, existingOrderID, conflict, err := s.repo.AcquireIdempotencyLock(ctx, idempotencyKey, req.UserID, requestHash)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to check idempotency key: %w", err)
+		}
+		if conflict {
+			return nil, false, ErrIdempotencyKeyConflict
+		}
+		if !locked {
+			if existingOrderID == nil {
+				return nil, false, ErrIdempotencyInFlight
+			}
+			existing, err := s.repo.GetOrderByID(ctx, *existingOrderID)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to get order: %w", err)
+			}
+			return existing, true, nil
+		}
 	}
 
 	// Create order ID and item IDs
@@ -36,14 +83,37 @@ func (s *OrderService) CreateOrder(ctx context.Context, req models.CreateOrderRe
 	// Calculate total and create order items
 	var totalAmount float64
 	var orderItems []models.OrderItem
+	var reservations []models.OrderReservation
 
 	for _, itemReq := range req.Items {
 		// Validate item
 		if itemReq.Quantity <= 0 {
-			return nil, fmt.Errorf("item quantity must be greater than 0")
+			s.releaseReservations(ctx, reservations)
+			return nil, false, fmt.Errorf("item quantity must be greater than 0")
 		}
 		if itemReq.UnitPrice < 0 {
-			return nil, fmt.Errorf("item unit price cannot be negative")
+			s.releaseReservations(ctx, reservations)
+			return nil, false, fmt.Errorf("item unit price cannot be negative")
+		}
+
+		if s.stockReserver != nil {
+			reservationID, err := s.stockReserver.Reserve(ctx, itemReq.ProductID, itemReq.Quantity)
+			if err != nil {
+				// Saga compensation: undo every reservation this order already holds
+				// before failing the request, so a partial order never ties up stock.
+				s.releaseReservations(ctx, reservations)
+				return nil, false, fmt.Errorf("failed to reserve stock for product %s: %w", itemReq.ProductID, err)
+			}
+			reservations = append(reservations, models.OrderReservation{
+				ID:            uuid.New(),
+				OrderID:       orderID,
+				ProductID:     itemReq.ProductID,
+				Quantity:      itemReq.Quantity,
+				ReservationID: reservationID,
+				State:         models.ReservationStatePending,
+				ExpiresAt:     now.Add(reservationTTL),
+				CreatedAt:     now,
+			})
 		}
 
 		// Calculate total price for this item
@@ -77,11 +147,25 @@ func (s *OrderService) CreateOrder(ctx context.Context, req models.CreateOrderRe
 	}
 
 	// Save to database
-	if err := s.repo.CreateOrder(ctx, order); err != nil {
-		return nil, fmt.Errorf("failed to create order: %w", err)
+	if err := s.repo.CreateOrder(ctx, order, reservations); err != nil {
+		s.releaseReservations(ctx, reservations)
+		return nil, false, fmt.Errorf("failed to create order: %w", err)
 	}
 
-	return order, nil
+	// The order committed, so the holds backing it are no longer at risk of being
+	// orphaned by a failed write; confirm them as permanent stock decrements.
+	s.confirmReservations(ctx, reservations)
+
+	if idempotencyKey != "" {
+		if err := s.repo.RecordIdempotencyResult(ctx, idempotencyKey, req.UserID, order.ID, requestHash, idempotencyResultTTL); err != nil {
+			// The order was created successfully; a failure to cache the idempotency
+			// result just means a retry arriving after the lock's TTL expires could create
+			// a duplicate, which is an acceptable tradeoff over failing the whole request.
+			return order, false, nil
+		}
+	}
+
+	return order, false, nil
 }
 
 // GetOrderByID retrieves an order by ID
@@ -134,6 +218,8 @@ func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID,
 		return fmt.Errorf("failed to update order status: %w", err)
 	}
 
+	s.publishStatusChange(ctx, orderID, currentOrder.UserID, currentOrder.Status, newStatus)
+
 	return nil
 }
 
@@ -155,18 +241,148 @@ func (s *OrderService) CancelOrder(ctx context.Context, orderID uuid.UUID) error
 		return fmt.Errorf("failed to cancel order: %w", err)
 	}
 
+	s.publishStatusChange(ctx, orderID, currentOrder.UserID, currentOrder.Status, models.OrderStatusCancelled)
+
+	if s.stockReserver != nil {
+		reservations, err := s.repo.GetReservationsByOrderID(ctx, orderID)
+		if err != nil {
+			log.Printf("order service: failed to load reservations for cancelled order %s: %v", orderID, err)
+		} else {
+			s.releaseReservations(ctx, reservations)
+		}
+	}
+
 	return nil
 }
 
-// GetOrdersByStatus retrieves orders by status (for admin use)
-func (s *OrderService) GetOrdersByStatus(ctx context.Context, status models.OrderStatus, page, limit int) (*models.OrdersResponse, error) {
-	// This would require a new repository method, for now return empty
-	return &models.OrdersResponse{
-		Orders: []models.Order{},
-		Total:  0,
-		Page:   page,
-		Limit:  limit,
-	}, nil
+// releaseReservations gives back every still-held reservation, e.g. because a sibling
+// reservation failed mid-order (saga compensation) or the order was cancelled. Each
+// release is best-effort: a failure is logged rather than surfaced, since the caller
+// has already committed to failing or cancelling the order.
+func (s *OrderService) releaseReservations(ctx context.Context, reservations []models.OrderReservation) {
+	if s.stockReserver == nil {
+		return
+	}
+	for _, reservation := range reservations {
+		if reservation.State != models.ReservationStatePending && reservation.State != models.ReservationStateConfirmed {
+			continue
+		}
+		if err := s.stockReserver.Release(ctx, reservation.ReservationID); err != nil {
+			log.Printf("order service: failed to release reservation %s: %v", reservation.ReservationID, err)
+			continue
+		}
+		if err := s.repo.UpdateReservationState(ctx, reservation.ID, models.ReservationStateReleased); err != nil {
+			log.Printf("order service: failed to mark reservation %s released: %v", reservation.ID, err)
+		}
+	}
+}
+
+// confirmReservations turns pending holds into permanent stock decrements once the
+// order they back has committed.
+func (s *OrderService) confirmReservations(ctx context.Context, reservations []models.OrderReservation) {
+	if s.stockReserver == nil {
+		return
+	}
+	for _, reservation := range reservations {
+		if err := s.stockReserver.Confirm(ctx, reservation.ReservationID); err != nil {
+			log.Printf("order service: failed to confirm reservation %s: %v", reservation.ReservationID, err)
+			continue
+		}
+		if err := s.repo.UpdateReservationState(ctx, reservation.ID, models.ReservationStateConfirmed); err != nil {
+			log.Printf("order service: failed to mark reservation %s confirmed: %v", reservation.ID, err)
+		}
+	}
+}
+
+// publishStatusChange best-effort notifies live subscribers of a status transition.
+// A delivery failure is only logged: the status update itself has already committed,
+// and this channel offers no delivery guarantee.
+func (s *OrderService) publishStatusChange(ctx context.Context, orderID, userID uuid.UUID, oldStatus, newStatus models.OrderStatus) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	event := models.OrderStatusEvent{
+		OrderID:    orderID,
+		UserID:     userID,
+		OldStatus:  oldStatus,
+		NewStatus:  newStatus,
+		OccurredAt: time.Now(),
+	}
+	if err := s.eventPublisher.PublishStatusChange(ctx, event); err != nil {
+		log.Printf("order service: failed to publish status change for order %s: %v", orderID, err)
+	}
+}
+
+// RunReservationReaper periodically releases reservations whose order never
+// confirmed them before expires_at, so abandoned orders don't tie up stock forever.
+// It's meant to be started as a background goroutine and runs until ctx is cancelled.
+func (s *OrderService) RunReservationReaper(ctx context.Context, interval time.Duration) {
+	if s.stockReserver == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stale, err := s.repo.ExpireStaleReservations(ctx, time.Now())
+			if err != nil {
+				log.Printf("order service: failed to expire stale reservations: %v", err)
+				continue
+			}
+			for _, reservation := range stale {
+				if err := s.stockReserver.Release(ctx, reservation.ReservationID); err != nil {
+					log.Printf("order service: failed to release expired reservation %s: %v", reservation.ReservationID, err)
+				}
+			}
+		}
+	}
+}
+
+// FulfillItems applies warehouse fulfillment updates (shipped quantities, per-item
+// status) to an order and derives the order's overall status from the result, e.g.
+// moving it to partially_shipped or delivered.
+func (s *OrderService) FulfillItems(ctx context.Context, orderID uuid.UUID, fulfillments []models.ItemFulfillment) (models.OrderStatus, error) {
+	if len(fulfillments) == 0 {
+		return "", fmt.Errorf("at least one item fulfillment is required")
+	}
+
+	newStatus, err := s.repo.FulfillItems(ctx, orderID, fulfillments)
+	if err != nil {
+		return "", fmt.Errorf("failed to fulfill items: %w", err)
+	}
+
+	return newStatus, nil
+}
+
+// RefundItem refunds (fully or partially) a single order item and returns the
+// order's remaining refundable amount.
+func (s *OrderService) RefundItem(ctx context.Context, orderID, itemID uuid.UUID, quantity int) (float64, error) {
+	if quantity <= 0 {
+		return 0, fmt.Errorf("refund quantity must be greater than 0")
+	}
+
+	remaining, err := s.repo.RefundItem(ctx, orderID, itemID, quantity)
+	if err != nil {
+		return 0, fmt.Errorf("failed to refund item: %w", err)
+	}
+
+	return remaining, nil
+}
+
+// SearchOrders runs an admin search over orders with the given filter, returning the
+// matching page and an opaque cursor for the next page.
+func (s *OrderService) SearchOrders(ctx context.Context, filter models.SearchFilter) ([]models.Order, string, error) {
+	orders, nextCursor, err := s.repo.SearchOrders(ctx, filter)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to search orders: %w", err)
+	}
+	return orders, nextCursor, nil
 }
 
 // isValidStatusTransition checks if a status transition is valid
@@ -182,6 +398,12 @@ func (s *OrderService) isValidStatusTransition(currentStatus, newStatus models.O
 		},
 		models.OrderStatusProcessing: {
 			models.OrderStatusShipped,
+			models.OrderStatusPartiallyShipped,
+			models.OrderStatusCancelled,
+		},
+		models.OrderStatusPartiallyShipped: {
+			models.OrderStatusShipped,
+			models.OrderStatusDelivered,
 			models.OrderStatusCancelled,
 		},
 		models.OrderStatusShipped: {
@@ -227,13 +449,9 @@ func (s *OrderService) canCancelOrder(status models.OrderStatus) bool {
 
 // GetOrderStats returns order statistics (for admin dashboard)
 func (s *OrderService) GetOrderStats(ctx context.Context) (*models.OrderStats, error) {
-	// This would require additional repository methods
-	// For now, return dummy data
-	return &models.OrderStats{
-		TotalOrders:     0,
-		PendingOrders:   0,
-		CompletedOrders: 0,
-		CancelledOrders: 0,
-		TotalRevenue:    0.0,
-	}, nil
+	stats, err := s.repo.GetOrderStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order stats: %w", err)
+	}
+	return stats, nil
 }
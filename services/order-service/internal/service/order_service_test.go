@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+)
+
+// idempotencyEntry is what fakeOrderRepository remembers about a claimed key: the
+// request hash that claimed it, and the order it resolved to (uuid.Nil while still
+// in flight).
+type idempotencyEntry struct {
+	hash    string
+	orderID uuid.UUID
+}
+
+// fakeOrderRepository is an in-memory OrderRepository for service-layer unit tests.
+type fakeOrderRepository struct {
+	orders map[uuid.UUID]*models.Order
+	// idempotencyKeys maps an idempotency key to the entry that claimed it.
+	idempotencyKeys map[string]idempotencyEntry
+}
+
+func newFakeOrderRepository() *fakeOrderRepository {
+	return &fakeOrderRepository{
+		orders:          make(map[uuid.UUID]*models.Order),
+		idempotencyKeys: make(map[string]idempotencyEntry),
+	}
+}
+
+func (f *fakeOrderRepository) CreateOrder(ctx context.Context, order *models.Order, reservations []models.OrderReservation) error {
+	f.orders[order.ID] = order
+	return nil
+}
+
+func (f *fakeOrderRepository) GetOrderByID(ctx context.Context, orderID uuid.UUID) (*models.Order, error) {
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, errors.New("order not found")
+	}
+	return order, nil
+}
+
+func (f *fakeOrderRepository) GetOrdersByUserID(ctx context.Context, userID uuid.UUID, page, limit int) ([]models.Order, int, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeOrderRepository) SearchOrders(ctx context.Context, filter models.SearchFilter) ([]models.Order, string, error) {
+	return nil, "", nil
+}
+
+func (f *fakeOrderRepository) GetOrderStats(ctx context.Context) (*models.OrderStats, error) {
+	return &models.OrderStats{}, nil
+}
+
+func (f *fakeOrderRepository) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, status models.OrderStatus) error {
+	order, ok := f.orders[orderID]
+	if !ok {
+		return errors.New("order not found")
+	}
+	order.Status = status
+	return nil
+}
+
+func (f *fakeOrderRepository) FulfillItems(ctx context.Context, orderID uuid.UUID, fulfillments []models.ItemFulfillment) (models.OrderStatus, error) {
+	return "", nil
+}
+
+func (f *fakeOrderRepository) RefundItem(ctx context.Context, orderID, itemID uuid.UUID, quantity int) (float64, error) {
+	return 0, nil
+}
+
+func (f *fakeOrderRepository) AcquireIdempotencyLock(ctx context.Context, key string, userID uuid.UUID, requestHash string) (bool, *uuid.UUID, bool, error) {
+	entry, ok := f.idempotencyKeys[key]
+	if !ok {
+		f.idempotencyKeys[key] = idempotencyEntry{hash: requestHash, orderID: uuid.Nil}
+		return true, nil, false, nil
+	}
+	if entry.hash != requestHash {
+		return false, nil, true, nil
+	}
+	if entry.orderID == uuid.Nil {
+		return false, nil, false, nil
+	}
+	return false, &entry.orderID, false, nil
+}
+
+func (f *fakeOrderRepository) RecordIdempotencyResult(ctx context.Context, key string, userID, orderID uuid.UUID, requestHash string, ttl time.Duration) error {
+	f.idempotencyKeys[key] = idempotencyEntry{hash: requestHash, orderID: orderID}
+	return nil
+}
+
+func (f *fakeOrderRepository) GetReservationsByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderReservation, error) {
+	return nil, nil
+}
+
+func (f *fakeOrderRepository) UpdateReservationState(ctx context.Context, id uuid.UUID, state models.ReservationState) error {
+	return nil
+}
+
+func (f *fakeOrderRepository) ExpireStaleReservations(ctx context.Context, now time.Time) ([]models.OrderReservation, error) {
+	return nil, nil
+}
+
+func newTestOrder(status models.OrderStatus) *models.Order {
+	return &models.Order{
+		ID:     uuid.New(),
+		UserID: uuid.New(),
+		Status: status,
+	}
+}
+
+func newTestCreateOrderRequest() models.CreateOrderRequest {
+	return models.CreateOrderRequest{
+		UserID: uuid.New(),
+		Items: []models.CreateOrderItemRequest{
+			{ProductID: uuid.New(), Quantity: 1, UnitPrice: 10},
+		},
+		Currency: "USD",
+	}
+}
+
+func TestOrderService_UpdateOrderStatus_Transitions(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   models.OrderStatus
+		next      models.OrderStatus
+		wantError bool
+	}{
+		{name: "pending to confirmed is allowed", current: models.OrderStatusPending, next: models.OrderStatusConfirmed, wantError: false},
+		{name: "pending to cancelled is allowed", current: models.OrderStatusPending, next: models.OrderStatusCancelled, wantError: false},
+		{name: "delivered to pending is rejected", current: models.OrderStatusDelivered, next: models.OrderStatusPending, wantError: true},
+		{name: "delivered to refunded is allowed", current: models.OrderStatusDelivered, next: models.OrderStatusRefunded, wantError: false},
+		{name: "cancelled is a terminal state", current: models.OrderStatusCancelled, next: models.OrderStatusConfirmed, wantError: true},
+		{name: "shipped to processing is rejected", current: models.OrderStatusShipped, next: models.OrderStatusProcessing, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeOrderRepository()
+			order := newTestOrder(tt.current)
+			repo.orders[order.ID] = order
+
+			svc := NewOrderService(repo, nil, nil)
+			err := svc.UpdateOrderStatus(context.Background(), order.ID, tt.next)
+
+			if tt.wantError && err == nil {
+				t.Fatalf("expected an error transitioning %s -> %s, got nil", tt.current, tt.next)
+			}
+			if !tt.wantError && err != nil {
+				t.Fatalf("expected no error transitioning %s -> %s, got: %v", tt.current, tt.next, err)
+			}
+			if !tt.wantError && order.Status != tt.next {
+				t.Fatalf("expected order status %s, got %s", tt.next, order.Status)
+			}
+		})
+	}
+}
+
+func TestOrderService_CreateOrder_IdempotentReplay(t *testing.T) {
+	repo := newFakeOrderRepository()
+	svc := NewOrderService(repo, nil, nil)
+	req := newTestCreateOrderRequest()
+
+	first, replayed, err := svc.CreateOrder(context.Background(), req, "key-1", "hash-a")
+	if err != nil {
+		t.Fatalf("unexpected error on first create: %v", err)
+	}
+	if replayed {
+		t.Fatalf("expected the first request with a new key not to be reported as replayed")
+	}
+
+	second, replayed, err := svc.CreateOrder(context.Background(), req, "key-1", "hash-a")
+	if err != nil {
+		t.Fatalf("unexpected error on retried create: %v", err)
+	}
+	if !replayed {
+		t.Fatalf("expected a retried request with the same idempotency key to be reported as replayed")
+	}
+	if second.ID != first.ID {
+		t.Fatalf("expected the retried request to return the original order %s, got %s", first.ID, second.ID)
+	}
+	if len(repo.orders) != 1 {
+		t.Fatalf("expected exactly one order to be created, got %d", len(repo.orders))
+	}
+}
+
+func TestOrderService_CreateOrder_ConflictingBodyRejected(t *testing.T) {
+	repo := newFakeOrderRepository()
+	svc := NewOrderService(repo, nil, nil)
+	req := newTestCreateOrderRequest()
+
+	if _, _, err := svc.CreateOrder(context.Background(), req, "key-1", "hash-a"); err != nil {
+		t.Fatalf("unexpected error on first create: %v", err)
+	}
+
+	_, _, err := svc.CreateOrder(context.Background(), req, "key-1", "hash-b")
+	if !errors.Is(err, ErrIdempotencyKeyConflict) {
+		t.Fatalf("expected ErrIdempotencyKeyConflict reusing a key with a different body, got: %v", err)
+	}
+}
+
+func TestOrderService_CreateOrder_RejectsEmptyItems(t *testing.T) {
+	repo := newFakeOrderRepository()
+	svc := NewOrderService(repo, nil, nil)
+	req := newTestCreateOrderRequest()
+	req.Items = nil
+
+	if _, _, err := svc.CreateOrder(context.Background(), req, "", ""); err == nil {
+		t.Fatal("expected an error creating an order with no items")
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// StockReserver holds and releases inventory on behalf of OrderService, so a product
+// can never be oversold across concurrent orders. Implementations typically call out
+// to the product/inventory service over gRPC or HTTP.
+type StockReserver interface {
+	// Reserve places a hold for quantity units of productID and returns an opaque
+	// reservation ID the caller must keep to release or confirm the hold later.
+	Reserve(ctx context.Context, productID uuid.UUID, quantity int) (reservationID string, err error)
+	// Release gives back a hold that is no longer needed, e.g. because a sibling
+	// reservation in the same order failed or the order was cancelled.
+	Release(ctx context.Context, reservationID string) error
+	// Confirm turns a hold into a permanent stock decrement once the order the
+	// reservation belongs to has committed.
+	Confirm(ctx context.Context, reservationID string) error
+}
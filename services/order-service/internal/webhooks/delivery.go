@@ -0,0 +1,18 @@
+package webhooks
+
+import (
+	"github.com/google/uuid"
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+)
+
+// Delivery is a due webhook_outbox row joined with the subscription it targets,
+// as returned by Store.FetchDueDeliveries.
+type Delivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventType      models.OrderEventType
+	Payload        []byte
+	Attempts       int
+	URL            string
+	Secret         string
+}
@@ -0,0 +1,137 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+)
+
+// Handler exposes CRUD HTTP endpoints for webhook subscriptions.
+type Handler struct {
+	store *Store
+}
+
+// NewHandler creates a new Handler
+func NewHandler(store *Store) *Handler {
+	return &Handler{store: store}
+}
+
+// CreateSubscription handles POST /api/v1/webhooks
+func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid JSON payload", http.StatusBadRequest)
+		return
+	}
+	if req.URL == "" || req.Secret == "" || len(req.Events) == 0 {
+		h.writeError(w, "url, secret, and at least one event are required", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	sub := &models.WebhookSubscription{
+		ID:        uuid.New(),
+		UserID:    req.UserID,
+		URL:       req.URL,
+		Secret:    req.Secret,
+		Events:    req.Events,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := h.store.CreateSubscription(r.Context(), sub); err != nil {
+		h.writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, sub, http.StatusCreated)
+}
+
+// ListSubscriptions handles GET /api/v1/webhooks?user_id=...
+func (h *Handler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	var userID *uuid.UUID
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		parsed, err := uuid.Parse(userIDStr)
+		if err != nil {
+			h.writeError(w, "Invalid user_id format", http.StatusBadRequest)
+			return
+		}
+		userID = &parsed
+	}
+
+	subs, err := h.store.ListSubscriptions(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, map[string]interface{}{"webhooks": subs}, http.StatusOK)
+}
+
+// UpdateSubscription handles PUT /api/v1/webhooks/{id}
+func (h *Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "Invalid webhook ID format", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, "Invalid JSON payload", http.StatusBadRequest)
+		return
+	}
+	if req.URL == "" || req.Secret == "" || len(req.Events) == 0 {
+		h.writeError(w, "url, secret, and at least one event are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateSubscription(r.Context(), id, req.URL, req.Secret, req.Events); err != nil {
+		if err.Error() == "webhook subscription not found" {
+			h.writeError(w, err.Error(), http.StatusNotFound)
+		} else {
+			h.writeError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.writeJSON(w, map[string]string{"message": "Webhook subscription updated successfully"}, http.StatusOK)
+}
+
+// DeleteSubscription handles DELETE /api/v1/webhooks/{id}
+func (h *Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "Invalid webhook ID format", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.DeleteSubscription(r.Context(), id); err != nil {
+		if err.Error() == "webhook subscription not found" {
+			h.writeError(w, err.Error(), http.StatusNotFound)
+		} else {
+			h.writeError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.writeJSON(w, map[string]string{"message": "Webhook subscription deleted successfully"}, http.StatusOK)
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, data interface{}, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode JSON response", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,15 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// sign computes the hex-encoded HMAC-SHA256 of payload using the subscriber's
+// secret, sent as the X-Signature header so the receiver can verify authenticity.
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
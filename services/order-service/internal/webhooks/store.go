@@ -0,0 +1,297 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+	"github.com/jondmarien/eco-market/services/order-service/internal/repository"
+	"github.com/lib/pq"
+)
+
+// eventsToArray converts event types to the []string form pq.Array needs to bind a
+// Postgres TEXT[] parameter.
+func eventsToArray(events []models.OrderEventType) pq.StringArray {
+	arr := make(pq.StringArray, len(events))
+	for i, e := range events {
+		arr[i] = string(e)
+	}
+	return arr
+}
+
+// arrayToEvents converts a scanned pq.StringArray back into OrderEventTypes.
+func arrayToEvents(arr pq.StringArray) []models.OrderEventType {
+	events := make([]models.OrderEventType, len(arr))
+	for i, e := range arr {
+		events[i] = models.OrderEventType(e)
+	}
+	return events
+}
+
+// Store persists webhook subscriptions and their delivery outbox in PostgreSQL.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a new Store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// CreateSubscription registers a new webhook subscription.
+func (s *Store) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (id, user_id, url, secret, events, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := s.db.ExecContext(ctx, query,
+		sub.ID, sub.UserID, sub.URL, sub.Secret, eventsToArray(sub.Events), sub.CreatedAt, sub.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// ListSubscriptions returns every subscription visible to userID: global
+// subscriptions (user_id IS NULL) plus any scoped to that user.
+func (s *Store) ListSubscriptions(ctx context.Context, userID *uuid.UUID) ([]models.WebhookSubscription, error) {
+	query := `
+		SELECT id, user_id, url, secret, events, created_at, updated_at
+		FROM webhook_subscriptions
+		WHERE user_id IS NULL OR user_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []models.WebhookSubscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// UpdateSubscription replaces a subscription's URL, secret, and subscribed events.
+func (s *Store) UpdateSubscription(ctx context.Context, id uuid.UUID, url, secret string, events []models.OrderEventType) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $1, secret = $2, events = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4`
+
+	result, err := s.db.ExecContext(ctx, query, url, secret, eventsToArray(events), id)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook subscription not found")
+	}
+	return nil
+}
+
+// DeleteSubscription removes a webhook subscription.
+func (s *Store) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook subscription not found")
+	}
+	return nil
+}
+
+// EnqueueForEvent implements repository.WebhookEnqueuer: it finds every subscription
+// matching event (global or scoped to event.UserID, subscribed to event.Type) and
+// writes one webhook_outbox row per match via executor, so the enqueue shares the
+// caller's transaction and can never be lost relative to the order write it's for.
+func (s *Store) EnqueueForEvent(ctx context.Context, executor repository.Executor, event models.OrderEvent) error {
+	query := `
+		SELECT id FROM webhook_subscriptions
+		WHERE (user_id IS NULL OR user_id = $1) AND $2 = ANY(events)`
+
+	rows, err := executor.QueryContext(ctx, query, event.UserID, string(event.Type))
+	if err != nil {
+		return fmt.Errorf("failed to find matching webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptionIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("failed to scan webhook subscription id: %w", err)
+		}
+		subscriptionIDs = append(subscriptionIDs, id)
+	}
+
+	if len(subscriptionIDs) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event payload: %w", err)
+	}
+
+	now := time.Now()
+	insertQuery := `
+		INSERT INTO webhook_outbox (id, subscription_id, event_type, payload, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, 0, $5, $6)`
+
+	for _, subscriptionID := range subscriptionIDs {
+		if _, err := executor.ExecContext(ctx, insertQuery, uuid.New(), subscriptionID, event.Type, payload, now, now); err != nil {
+			return fmt.Errorf("failed to enqueue webhook delivery for subscription %s: %w", subscriptionID, err)
+		}
+	}
+
+	return nil
+}
+
+// FetchDueDeliveries returns up to limit undelivered, non-dead-lettered deliveries
+// whose next_attempt_at has passed, joined with their subscription's URL and secret,
+// for the worker pool to attempt. Matching rows are locked with FOR UPDATE SKIP
+// LOCKED and their next_attempt_at is pushed out by claimTTL before the claiming
+// transaction commits, so a concurrent poll from another worker skips rows already
+// claimed instead of fetching and delivering them a second time.
+func (s *Store) FetchDueDeliveries(ctx context.Context, limit int) ([]Delivery, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin claim transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT o.id, o.subscription_id, o.event_type, o.payload, o.attempts, s.url, s.secret
+		FROM webhook_outbox o
+		JOIN webhook_subscriptions s ON s.id = o.subscription_id
+		WHERE o.delivered_at IS NULL AND o.dead_lettered = false AND o.next_attempt_at <= $1
+		ORDER BY o.next_attempt_at ASC
+		LIMIT $2
+		FOR UPDATE OF o SKIP LOCKED`
+
+	rows, err := tx.QueryContext(ctx, query, time.Now(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch due webhook deliveries: %w", err)
+	}
+
+	var deliveries []Delivery
+	var claimedIDs pq.StringArray
+	for rows.Next() {
+		var d Delivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventType, &d.Payload, &d.Attempts, &d.URL, &d.Secret); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+		claimedIDs = append(claimedIDs, d.ID.String())
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("failed to iterate due webhook deliveries: %w", err)
+	}
+	rows.Close()
+
+	// Push next_attempt_at out past claimTTL as an in-flight marker, inside the same
+	// locking transaction, so no other worker's concurrent poll can select these rows
+	// again until this delivery attempt either succeeds or times out.
+	if len(claimedIDs) > 0 {
+		claimQuery := `UPDATE webhook_outbox SET next_attempt_at = $1 WHERE id = ANY($2::uuid[])`
+		if _, err := tx.ExecContext(ctx, claimQuery, time.Now().Add(claimTTL), claimedIDs); err != nil {
+			return nil, fmt.Errorf("failed to claim due webhook deliveries: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit claim transaction: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// MarkDelivered records a successful delivery.
+func (s *Store) MarkDelivered(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE webhook_outbox SET delivered_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery delivered: %w", err)
+	}
+	return nil
+}
+
+// ScheduleRetry bumps attempts and pushes next_attempt_at out to nextAttempt, or
+// marks the delivery dead-lettered if attempts has reached maxAttempts.
+func (s *Store) ScheduleRetry(ctx context.Context, id uuid.UUID, attempts int, nextAttempt time.Time, maxAttempts int) error {
+	deadLettered := attempts >= maxAttempts
+	query := `
+		UPDATE webhook_outbox
+		SET attempts = $1, next_attempt_at = $2, dead_lettered = $3
+		WHERE id = $4`
+	if _, err := s.db.ExecContext(ctx, query, attempts, nextAttempt, deadLettered, id); err != nil {
+		return fmt.Errorf("failed to schedule webhook delivery retry: %w", err)
+	}
+	return nil
+}
+
+// InitializeSchema creates the webhook_subscriptions and webhook_outbox tables.
+func (s *Store) InitializeSchema(ctx context.Context) error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		user_id UUID,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT[] NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS webhook_outbox (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+		event_type VARCHAR(50) NOT NULL,
+		payload JSONB NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		delivered_at TIMESTAMP WITH TIME ZONE,
+		dead_lettered BOOLEAN NOT NULL DEFAULT false,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_user_id ON webhook_subscriptions(user_id);
+	CREATE INDEX IF NOT EXISTS idx_webhook_outbox_due ON webhook_outbox(next_attempt_at) WHERE delivered_at IS NULL AND dead_lettered = false;
+	`
+
+	if _, err := s.db.ExecContext(ctx, schema); err != nil {
+		return fmt.Errorf("failed to initialize webhook schema: %w", err)
+	}
+	return nil
+}
+
+// scanSubscription scans a row shaped like the SELECT list used by ListSubscriptions.
+func scanSubscription(rows *sql.Rows) (models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	var events pq.StringArray
+	if err := rows.Scan(&sub.ID, &sub.UserID, &sub.URL, &sub.Secret, &events, &sub.CreatedAt, &sub.UpdatedAt); err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("failed to scan webhook subscription: %w", err)
+	}
+	sub.Events = arrayToEvents(events)
+	return sub, nil
+}
@@ -0,0 +1,129 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// pollInterval is how often each worker checks for due deliveries.
+	pollInterval = 2 * time.Second
+	// deliveryTimeout bounds how long a single webhook POST may take.
+	deliveryTimeout = 10 * time.Second
+	// backoffBase is the delay before the first retry; it doubles each attempt.
+	backoffBase = 5 * time.Second
+	// backoffMax caps how long a retry can be pushed out.
+	backoffMax = 30 * time.Minute
+	// batchSize bounds how many due deliveries a single poll pulls per worker.
+	batchSize = 20
+	// claimTTL is how long FetchDueDeliveries reserves a row for the worker that
+	// claimed it before another poll is allowed to pick it back up. A worker attempts
+	// its claimed batch one delivery at a time, so this must exceed batchSize ×
+	// deliveryTimeout for a slow-but-healthy delivery at the end of a batch not to be
+	// claimed twice.
+	claimTTL = batchSize*deliveryTimeout + 20*time.Second
+)
+
+// Pool delivers queued webhook_outbox rows with a fixed number of concurrent
+// workers, retrying failed deliveries with exponential backoff up to maxAttempts
+// before dead-lettering them.
+type Pool struct {
+	store       *Store
+	client      *http.Client
+	workers     int
+	maxAttempts int
+}
+
+// NewPool creates a new Pool
+func NewPool(store *Store, workers, maxAttempts int) *Pool {
+	return &Pool{
+		store:       store,
+		client:      &http.Client{Timeout: deliveryTimeout},
+		workers:     workers,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Run starts the configured number of worker goroutines and blocks until ctx is
+// cancelled.
+func (p *Pool) Run(ctx context.Context) {
+	for i := 0; i < p.workers; i++ {
+		go p.runWorker(ctx)
+	}
+	<-ctx.Done()
+}
+
+func (p *Pool) runWorker(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.deliverDue(ctx)
+		}
+	}
+}
+
+// deliverDue fetches and attempts a batch of due deliveries, one at a time.
+func (p *Pool) deliverDue(ctx context.Context) {
+	deliveries, err := p.store.FetchDueDeliveries(ctx, batchSize)
+	if err != nil {
+		log.Printf("webhooks: failed to fetch due deliveries: %v", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		p.attempt(ctx, delivery)
+	}
+}
+
+// attempt sends delivery's payload, marking it delivered on success or scheduling a
+// backed-off retry (or dead-lettering it) on failure.
+func (p *Pool) attempt(ctx context.Context, delivery Delivery) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		log.Printf("webhooks: failed to build request for delivery %s: %v", delivery.ID, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", sign(delivery.Secret, delivery.Payload))
+
+	resp, err := p.client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if markErr := p.store.MarkDelivered(ctx, delivery.ID); markErr != nil {
+			log.Printf("webhooks: failed to mark delivery %s delivered: %v", delivery.ID, markErr)
+		}
+		return
+	}
+
+	if err != nil {
+		log.Printf("webhooks: delivery %s failed: %v", delivery.ID, err)
+	} else {
+		log.Printf("webhooks: delivery %s rejected with status %d", delivery.ID, resp.StatusCode)
+	}
+
+	attempts := delivery.Attempts + 1
+	if scheduleErr := p.store.ScheduleRetry(ctx, delivery.ID, attempts, nextAttemptAt(attempts), p.maxAttempts); scheduleErr != nil {
+		log.Printf("webhooks: failed to schedule retry for delivery %s: %v", delivery.ID, scheduleErr)
+	}
+}
+
+// nextAttemptAt computes an exponential backoff delay for the given attempt count,
+// capped at backoffMax.
+func nextAttemptAt(attempts int) time.Time {
+	delay := backoffBase << uint(attempts-1)
+	if delay > backoffMax || delay <= 0 {
+		delay = backoffMax
+	}
+	return time.Now().Add(delay)
+}
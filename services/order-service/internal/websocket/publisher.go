@@ -0,0 +1,42 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jondmarien/eco-market/services/order-service/internal/models"
+)
+
+// channelForUser is the Redis Pub/Sub channel a user's order status events are
+// published to, and that StreamHandler subscribes to on their behalf.
+func channelForUser(userID string) string {
+	return fmt.Sprintf("orders:user:%s", userID)
+}
+
+// RedisEventPublisher implements service.OrderEventPublisher by publishing order
+// status transitions to a per-user Redis Pub/Sub channel, for StreamHandler to relay
+// to connected WebSocket clients.
+type RedisEventPublisher struct {
+	client *redis.Client
+}
+
+// NewRedisEventPublisher creates a new RedisEventPublisher
+func NewRedisEventPublisher(client *redis.Client) *RedisEventPublisher {
+	return &RedisEventPublisher{client: client}
+}
+
+// PublishStatusChange publishes event to the channel for event.UserID.
+func (p *RedisEventPublisher) PublishStatusChange(ctx context.Context, event models.OrderStatusEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order status event: %w", err)
+	}
+
+	if err := p.client.Publish(ctx, channelForUser(event.UserID.String()), payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish order status event: %w", err)
+	}
+
+	return nil
+}
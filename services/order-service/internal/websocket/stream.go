@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// writeWait bounds how long a single frame write (including pings) may block.
+	writeWait = 10 * time.Second
+	// pongWait bounds how long we'll wait for a pong before considering the
+	// connection dead.
+	pongWait = 60 * time.Second
+	// pingPeriod must be less than pongWait so a ping lands before the read deadline.
+	pingPeriod = (pongWait * 9) / 10
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// Frontends and other local services connect from other origins in this repo's
+	// dev/demo deployments; tighten this with an allow-list before exposing publicly.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// StreamHandler upgrades HTTP requests to WebSocket connections that stream live
+// order status events for a single user, subscribing to their Redis Pub/Sub channel
+// and forwarding each message as a JSON frame.
+type StreamHandler struct {
+	redis *redis.Client
+}
+
+// NewStreamHandler creates a new StreamHandler
+func NewStreamHandler(redis *redis.Client) *StreamHandler {
+	return &StreamHandler{redis: redis}
+}
+
+// Stream handles GET /api/v1/orders/stream?user_id=.... In production the user ID
+// would come from an authenticated session/JWT middleware rather than a query
+// parameter; this mirrors how GetOrders currently identifies the caller.
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.URL.Query().Get("user_id")
+	if userIDStr == "" {
+		http.Error(w, "user_id parameter is required", http.StatusBadRequest)
+		return
+	}
+	if _, err := uuid.Parse(userIDStr); err != nil {
+		http.Error(w, "invalid user_id format", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("websocket: upgrade failed: %v", err)
+		return
+	}
+
+	sub := h.redis.Subscribe(r.Context(), channelForUser(userIDStr))
+	go h.pump(conn, sub)
+}
+
+// pump relays messages from the user's Redis Pub/Sub channel to the WebSocket
+// connection and maintains a ping/pong keepalive until either side disconnects, at
+// which point it unsubscribes and closes the connection.
+func (h *StreamHandler) pump(conn *websocket.Conn, sub *redis.PubSub) {
+	defer sub.Close()
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	// This is a server-push-only stream, so client frames are discarded; reading
+	// them is still necessary to process pongs and notice the connection closing.
+	go func() {
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg.Payload)); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
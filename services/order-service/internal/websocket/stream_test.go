@@ -0,0 +1,73 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	gorillaws "github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/jondmarien/eco-market/services/order-service/internal/middleware"
+)
+
+// TestStreamUpgradeThroughMiddlewareChain guards against a regression where a
+// non-transparent logging middleware wrapper (one that doesn't implement
+// http.Hijacker) makes every WebSocket upgrade fail with "response does not
+// implement http.Hijacker", by dialing through the same Logging/Recovery chain
+// app.go registers on the router instead of calling the handler directly.
+func TestStreamUpgradeThroughMiddlewareChain(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	defer redisClient.Close()
+
+	handler := NewStreamHandler(redisClient)
+
+	router := mux.NewRouter()
+	router.Use(middleware.Logging(logrus.New()), middleware.Recovery(logrus.New()))
+	router.HandleFunc("/stream", handler.Stream).Methods("GET")
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/stream?user_id=" + uuid.New().String()
+	conn, resp, err := gorillaws.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		t.Fatalf("websocket handshake through full middleware chain failed (status %d): %v", status, err)
+	}
+	conn.Close()
+}
+
+// TestStreamRequiresUserID checks the handler still behaves as a normal
+// (non-hijacked) HTTP handler through the same middleware chain when the upgrade
+// never happens.
+func TestStreamRequiresUserID(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	defer redisClient.Close()
+
+	handler := NewStreamHandler(redisClient)
+
+	router := mux.NewRouter()
+	router.Use(middleware.Logging(logrus.New()), middleware.Recovery(logrus.New()))
+	router.HandleFunc("/stream", handler.Stream).Methods("GET")
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/stream")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for missing user_id, got %d", resp.StatusCode)
+	}
+}